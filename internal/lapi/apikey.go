@@ -0,0 +1,147 @@
+package lapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// APIKeyLocation indica onde a API key é enviada na requisição.
+type APIKeyLocation int
+
+const (
+	// APIKeyInHeader envia a API key em um header HTTP (ex: X-API-Key).
+	APIKeyInHeader APIKeyLocation = iota
+
+	// APIKeyInQuery envia a API key como query parameter (ex: ?api_key=).
+	APIKeyInQuery
+
+	// APIKeyInCookie envia a API key em um cookie.
+	APIKeyInCookie
+)
+
+// apiKeyAuth é uma implementação de ChallengeAuthenticator para autenticação por API key.
+// A chave é injetada em um header, query parameter ou cookie e, quando várias chaves
+// são configuradas, é trocada pela próxima sempre que a cota da atual é atingida.
+type apiKeyAuth struct {
+	in     APIKeyLocation
+	name   string
+	prefix string
+	keys   []string
+
+	// quotaStatus são os códigos de status que indicam que a cota da chave foi atingida.
+	quotaStatus []int
+
+	mu      sync.Mutex
+	current int
+}
+
+// NewAPIKeyAuth cria um Authenticator por API key.
+//
+// Parâmetros:
+//   - in: Onde a chave será enviada (APIKeyInHeader, APIKeyInQuery ou APIKeyInCookie)
+//   - name: Nome do header, query parameter ou cookie
+//   - keys: Uma ou mais API keys, usadas em rodízio quando a cota é atingida
+//
+// Exemplo:
+//
+//	auth := NewAPIKeyAuth(APIKeyInQuery, "api_key", "chave-1", "chave-2")
+//	r.SetAuthenticator(auth)
+func NewAPIKeyAuth(in APIKeyLocation, name string, keys ...string) *apiKeyAuth {
+	return &apiKeyAuth{
+		in:          in,
+		name:        name,
+		keys:        keys,
+		quotaStatus: []int{http.StatusTooManyRequests},
+	}
+}
+
+// WithPrefix define um prefixo adicionado antes da chave.
+// Útil para APIs que esperam, por exemplo, "Authorization: ApiKey <chave>".
+//
+// Exemplo:
+//
+//	NewAPIKeyAuth(APIKeyInHeader, "Authorization", "chave").WithPrefix("ApiKey ")
+//
+// Retorna o próprio Authenticator para permitir encadeamento de métodos.
+func (a *apiKeyAuth) WithPrefix(prefix string) *apiKeyAuth {
+	a.prefix = prefix
+	return a
+}
+
+// WithQuotaStatus define os códigos de status que indicam que a cota da chave foi atingida.
+// Por padrão, apenas 429 Too Many Requests.
+//
+// Exemplo:
+//
+//	NewAPIKeyAuth(APIKeyInHeader, "X-API-Key", "k1", "k2").WithQuotaStatus(403, 429)
+//
+// Retorna o próprio Authenticator para permitir encadeamento de métodos.
+func (a *apiKeyAuth) WithQuotaStatus(codes ...int) *apiKeyAuth {
+	a.quotaStatus = codes
+	return a
+}
+
+// Authenticate injeta a API key atual na requisição.
+func (a *apiKeyAuth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	if len(a.keys) == 0 {
+		a.mu.Unlock()
+		return nil
+	}
+	value := a.prefix + a.keys[a.current]
+	a.mu.Unlock()
+
+	switch a.in {
+	case APIKeyInQuery:
+		query := req.URL.Query()
+		query.Set(a.name, value)
+		req.URL.RawQuery = query.Encode()
+	case APIKeyInCookie:
+		cookies := req.Cookies()
+		req.Header.Del("Cookie")
+		for _, cookie := range cookies {
+			if cookie.Name != a.name {
+				req.AddCookie(cookie)
+			}
+		}
+		req.AddCookie(&http.Cookie{Name: a.name, Value: value})
+	default:
+		req.Header.Set(a.name, value)
+	}
+	return nil
+}
+
+// Challenge troca a API key pela próxima quando a resposta indica que a cota foi atingida.
+// Retorna true quando existe outra chave para reenviar a requisição.
+func (a *apiKeyAuth) Challenge(resp *http.Response) bool {
+	if !slices.Contains(a.quotaStatus, resp.StatusCode) || len(a.keys) < 2 {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Another request may have rotated the key already
+	if resp.Request != nil && a.keyFrom(resp.Request) == a.prefix+a.keys[a.current] {
+		a.current = (a.current + 1) % len(a.keys)
+	}
+	return true
+}
+
+// keyFrom retorna o valor da API key enviado na requisição.
+func (a *apiKeyAuth) keyFrom(req *http.Request) string {
+	switch a.in {
+	case APIKeyInQuery:
+		return req.URL.Query().Get(a.name)
+	case APIKeyInCookie:
+		cookie, err := req.Cookie(a.name)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	default:
+		return strings.TrimSpace(req.Header.Get(a.name))
+	}
+}
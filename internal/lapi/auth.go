@@ -1,5 +1,72 @@
 package lapi
 
+import (
+	"log"
+	"net/http"
+)
+
+// Authenticator é uma interface que representa um mecanismo de autenticação.
+// Authenticate é chamado a cada envio da requisição, depois que headers e body
+// estão definidos, inclusive quando a requisição é reenviada.
+//
+// Exemplo de uso:
+//
+//	m.SetAuthenticator(NewBasicAuth("usuario", "senha"))
+type Authenticator interface {
+	// Authenticate adiciona as credenciais à requisição HTTP.
+	Authenticate(req *http.Request) error
+}
+
+// ChallengeAuthenticator é um Authenticator que responde a desafios do servidor.
+// Quando a resposta tem status de erro (>= 400), Challenge recebe a resposta e
+// indica se a requisição deve ser reenviada com novas credenciais.
+type ChallengeAuthenticator interface {
+	Authenticator
+
+	// Challenge processa a resposta de erro (ex: o header WWW-Authenticate de um 401).
+	// Retorna true quando a requisição deve ser reenviada.
+	Challenge(resp *http.Response) bool
+}
+
+// basicAuth é uma implementação de Authenticator para HTTP Basic (RFC 7617).
+type basicAuth struct {
+	username string
+	password string
+}
+
+// NewBasicAuth cria um Authenticator HTTP Basic.
+//
+// Parâmetros:
+//   - username: Nome de usuário
+//   - password: Senha
+//
+// Exemplo:
+//
+//	r.SetAuthenticator(NewBasicAuth("usuario", "senha"))
+func NewBasicAuth(username, password string) *basicAuth {
+	return &basicAuth{
+		username: username,
+		password: password,
+	}
+}
+
+// Authenticate adiciona o header Authorization: Basic à requisição.
+func (a *basicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// RefreshFunc é a função usada para renovar o token de acesso.
+// Recebe o token de atualização atual e retorna o novo par de tokens.
+//
+// Exemplo:
+//
+//	m.SetRefreshFunc(func(refreshToken string) (string, string, error) {
+//	    // chama o endpoint de refresh da API
+//	    return novoToken, novoRefreshToken, nil
+//	})
+type RefreshFunc func(refreshToken string) (token string, newRefreshToken string, err error)
+
 // SetAccessToken define o token de acesso JWT para a requisição.
 // O token será automaticamente adicionado como Bearer token no header Authorization.
 //
@@ -11,6 +78,7 @@ package lapi
 //	m.SetAccessToken("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...")
 func (m *model) SetAccessToken(token string) {
 	m.Auth.Token = token
+	m.saveTokens()
 }
 
 // SetRefreshToken define o token de atualização JWT para a requisição.
@@ -24,6 +92,7 @@ func (m *model) SetAccessToken(token string) {
 //	m.SetRefreshToken("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...")
 func (m *model) SetRefreshToken(refreshToken string) {
 	m.Auth.RefreshToken = refreshToken
+	m.saveTokens()
 }
 
 // SetAuth define o token de acesso e o token de atualização JWT para a requisição.
@@ -42,10 +111,133 @@ func (m *model) SetRefreshToken(refreshToken string) {
 func (m *model) SetAuth(token, refreshToken string) {
 	m.Auth.Token = token
 	m.Auth.RefreshToken = refreshToken
+	m.saveTokens()
+}
+
+// SetAuthenticator define o mecanismo de autenticação da requisição.
+// Quando definido, substitui o envio do token de acesso como Bearer.
+//
+// Parâmetros:
+//   - auth: Implementação de Authenticator (ex: NewBasicAuth, NewDigestAuth)
+//
+// Exemplo:
+//
+//	m.SetAuthenticator(NewDigestAuth("usuario", "senha"))
+func (m *model) SetAuthenticator(auth Authenticator) {
+	m.request.auth = auth
+}
+
+// SetBasicAuth define a autenticação HTTP Basic para a requisição.
+// Esta função é um atalho para SetAuthenticator(NewBasicAuth(username, password)).
+//
+// Parâmetros:
+//   - username: Nome de usuário
+//   - password: Senha
+//
+// Exemplo:
+//
+//	m.SetBasicAuth("usuario", "senha")
+func (m *model) SetBasicAuth(username, password string) {
+	m.SetAuthenticator(NewBasicAuth(username, password))
+}
+
+// SetDigestAuth define a autenticação HTTP Digest (RFC 7616) para a requisição.
+// O desafio 401 do servidor é tratado automaticamente e o nonce é reutilizado
+// entre as chamadas.
+// Esta função é um atalho para SetAuthenticator(NewDigestAuth(username, password)).
+//
+// Parâmetros:
+//   - username: Nome de usuário
+//   - password: Senha
+//
+// Exemplo:
+//
+//	m.SetDigestAuth("usuario", "senha")
+func (m *model) SetDigestAuth(username, password string) {
+	m.SetAuthenticator(NewDigestAuth(username, password))
+}
+
+// SetAPIKey define a autenticação por API key para a requisição.
+// Quando mais de uma chave é informada, elas são usadas em rodízio sempre que
+// a API responder que a cota foi atingida (429 Too Many Requests).
+// Esta função é um atalho para SetAuthenticator(NewAPIKeyAuth(in, name, keys...)).
+//
+// Parâmetros:
+//   - in: Onde a chave será enviada (APIKeyInHeader, APIKeyInQuery ou APIKeyInCookie)
+//   - name: Nome do header, query parameter ou cookie
+//   - keys: Uma ou mais API keys
+//
+// Exemplo:
+//
+//	m.SetAPIKey(APIKeyInHeader, "X-API-Key", "chave-1", "chave-2")
+func (m *model) SetAPIKey(in APIKeyLocation, name string, keys ...string) {
+	m.SetAuthenticator(NewAPIKeyAuth(in, name, keys...))
+}
+
+// SetSigner define o mecanismo de assinatura das requisições (ex: HMAC).
+// A assinatura é calculada depois da autenticação e recalculada a cada reenvio.
+//
+// Parâmetros:
+//   - signer: Implementação de Signer (ex: NewHMACSigner)
+//
+// Exemplo:
+//
+//	m.SetSigner(NewHMACSigner([]byte("segredo")))
+func (m *model) SetSigner(signer Signer) {
+	m.request.signer = signer
+}
+
+// SetRefreshFunc define a função usada para renovar o token de acesso.
+// Quando definida, uma resposta 401 faz com que o token seja renovado
+// automaticamente e a requisição seja repetida uma única vez.
+//
+// Parâmetros:
+//   - fn: Função que recebe o token de atualização e retorna o novo par de tokens
+//
+// Exemplo:
+//
+//	m.SetRefreshFunc(func(refreshToken string) (string, string, error) {
+//	    return "novo-token", "novo-refresh-token", nil
+//	})
+func (m *model) SetRefreshFunc(fn RefreshFunc) {
+	m.refresh = fn
+}
+
+// SetTokenStore define o armazenamento persistente dos tokens.
+// Os tokens armazenados são carregados imediatamente e, a partir daí,
+// salvos sempre que forem definidos ou renovados.
+//
+// Parâmetros:
+//   - store: Implementação de TokenStore (ex: NewFileTokenStore, NewMemoryTokenStore)
+//
+// Exemplo:
+//
+//	store, _ := NewFileTokenStore("tokens.json", "LAPI_TOKEN_KEY")
+//	if err := m.SetTokenStore(store); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Retorna um erro se não for possível carregar os tokens armazenados.
+func (m *model) SetTokenStore(store TokenStore) error {
+	m.tokenStore = store
+	if store == nil {
+		return nil
+	}
+
+	tokens, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if tokens.Token != "" || tokens.RefreshToken != "" {
+		m.Auth.Token = tokens.Token
+		m.Auth.RefreshToken = tokens.RefreshToken
+	}
+	return nil
 }
 
 // RevalidateToken revalida o token de acesso usando o token de atualização.
 // Esta função é chamada automaticamente quando o token de acesso expira.
+// Os novos tokens são salvos no TokenStore, se configurado.
 //
 // Retorna:
 //   - string: Novo token de acesso JWT
@@ -54,6 +246,34 @@ func (m *model) SetAuth(token, refreshToken string) {
 //
 //	newToken := m.RevalidateToken()
 func (m *model) RevalidateToken() string {
-	// TODO: Implement the revalidation of the token
+	if m.refresh == nil || m.Auth.RefreshToken == "" {
+		return m.Auth.Token
+	}
+
+	token, refreshToken, err := m.refresh(m.Auth.RefreshToken)
+	if err != nil {
+		log.Println(err.Error())
+		return m.Auth.Token
+	}
+
+	m.Auth.Token = token
+	if refreshToken != "" {
+		m.Auth.RefreshToken = refreshToken
+	}
+	m.saveTokens()
 	return m.Auth.Token
 }
+
+// saveTokens salva os tokens atuais no TokenStore, se configurado.
+func (m *model) saveTokens() {
+	if m.tokenStore == nil {
+		return
+	}
+	err := m.tokenStore.Save(Tokens{
+		Token:        m.Auth.Token,
+		RefreshToken: m.Auth.RefreshToken,
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
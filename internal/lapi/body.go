@@ -3,6 +3,7 @@ package lapi
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/url"
 	"strings"
@@ -22,12 +23,16 @@ import (
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
 func (r *request) SetBody(body io.Reader) *request {
+	r.setErr("body", nil)
 	r.body = body
+	r.source = nil
+	r.contentType = ""
 	return r
 }
 
 // SetBodyString define o corpo da requisição HTTP como uma string.
 // Útil para enviar dados em formato texto simples.
+// O header Content-Type será automaticamente definido como text/plain; charset=utf-8.
 //
 // Parâmetros:
 //   - body: String contendo o corpo da requisição
@@ -38,13 +43,17 @@ func (r *request) SetBody(body io.Reader) *request {
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
 func (r *request) SetBodyString(body string) *request {
+	r.setErr("body", nil)
 	r.body = strings.NewReader(body)
+	r.source = nil
+	r.contentType = "text/plain; charset=utf-8"
 	return r
 }
 
 // SetBodyJSON define o corpo da requisição HTTP como um JSON.
 // O valor será automaticamente convertido para uma string JSON.
-// O header Content-Type será automaticamente definido como application/json.
+// O header Content-Type será automaticamente definido como application/json; charset=utf-8,
+// a menos que já tenha sido definido explicitamente.
 //
 // Parâmetros:
 //   - body: Estrutura ou mapa a ser convertido para JSON
@@ -58,19 +67,25 @@ func (r *request) SetBodyString(body string) *request {
 //	})
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
-// Se houver erro na conversão para JSON, o corpo não será definido.
+// Se houver erro na conversão para JSON, o corpo não será definido
+// e o erro será registrado e retornado por Err.
 func (r *request) SetBodyJSON(body interface{}) *request {
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
+		r.setErr("body", fmt.Errorf("lapi: não foi possível converter o corpo para JSON: %w", err))
 		return r
 	}
+	r.setErr("body", nil)
 	r.body = bytes.NewReader(jsonBody)
+	r.source = nil
+	r.contentType = "application/json; charset=utf-8"
 	return r
 }
 
 // SetBodyFormData define o corpo da requisição HTTP como um FormData.
 // Os valores serão automaticamente codificados para URL.
-// O header Content-Type será automaticamente definido como application/x-www-form-urlencoded.
+// O header Content-Type será automaticamente definido como application/x-www-form-urlencoded,
+// a menos que já tenha sido definido explicitamente.
 //
 // Parâmetros:
 //   - body: Mapa de campos do formulário (chave -> valor)
@@ -90,6 +105,48 @@ func (r *request) SetBodyFormData(body map[string]string) *request {
 		formData.Add(key, value)
 	}
 
+	r.setErr("body", nil)
 	r.body = strings.NewReader(formData.Encode())
+	r.source = nil
+	r.contentType = "application/x-www-form-urlencoded"
 	return r
 }
+
+// SetMultipart define o corpo da requisição HTTP como multipart/form-data.
+// O conteúdo é transmitido durante o envio e o header Content-Type, com o
+// boundary, é definido automaticamente.
+//
+// Parâmetros:
+//   - body: Construtor multipart criado com NewMultipart
+//
+// Exemplo:
+//
+//	r.SetMultipart(NewMultipart().
+//	    Field("name", "John").
+//	    File("avatar", "/tmp/avatar.png"))
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetMultipart(body *multipartBuilder) *request {
+	if err := body.Err(); err != nil {
+		r.setErr("body", err)
+		return r
+	}
+	r.setErr("body", nil)
+	r.body = nil
+	r.source = body.source()
+	r.contentType = ""
+	return r
+}
+
+// bodySource gera o corpo de uma requisição a cada envio, permitindo
+// transmitir o conteúdo sob demanda e recriá-lo para reenvio.
+type bodySource struct {
+	// open abre um novo leitor do corpo.
+	open func() (io.ReadCloser, error)
+
+	// replayable indica se open pode ser chamada mais de uma vez.
+	replayable bool
+
+	// contentType é o valor do header Content-Type do corpo.
+	contentType string
+}
@@ -0,0 +1,261 @@
+package lapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Codec é uma interface que representa a codificação de um media type.
+// É usada para codificar o payload das requisições e decodificar as respostas.
+//
+// Exemplo de uso:
+//
+//	RegisterCodec(yamlCodec{})
+//	err := m.Post("/config", &payload, &dest, WithContentType("application/yaml"))
+type Codec interface {
+	// MediaType retorna o media type tratado pelo codec (ex: "application/json").
+	MediaType() string
+
+	// Encode escreve v codificado em w.
+	Encode(w io.Writer, v interface{}) error
+
+	// Decode lê de r e decodifica o conteúdo em v.
+	Decode(r io.Reader, v interface{}) error
+}
+
+// codecRegistry é o registro de codecs por media type.
+// A ordem de registro define a preferência no header Accept.
+type codecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+	order  []string
+}
+
+// codecs é o registro global de codecs, com JSON, XML, form-urlencoded e texto.
+// O CSV não é registrado para ficar fora do Accept padrão, mas é resolvido por lookup.
+var codecs = newCodecRegistry(jsonCodec{}, xmlCodec{}, formCodec{}, textCodec{})
+
+// newCodecRegistry cria um registro com os codecs informados.
+func newCodecRegistry(list ...Codec) *codecRegistry {
+	r := &codecRegistry{codecs: make(map[string]Codec)}
+	for _, c := range list {
+		r.register(c)
+	}
+	return r
+}
+
+// RegisterCodec registra um codec para o seu media type.
+// Se já existir um codec para o mesmo media type, ele é substituído.
+//
+// Parâmetros:
+//   - c: Implementação de Codec
+//
+// Exemplo:
+//
+//	RegisterCodec(msgpackCodec{})
+func RegisterCodec(c Codec) {
+	codecs.register(c)
+}
+
+// register adiciona ou substitui um codec no registro.
+func (r *codecRegistry) register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType := strings.ToLower(c.MediaType())
+	if _, ok := r.codecs[mediaType]; !ok {
+		r.order = append(r.order, mediaType)
+	}
+	r.codecs[mediaType] = c
+}
+
+// lookup retorna o codec do Content-Type informado.
+// Media types com sufixo +json ou +xml (ex: application/problem+json) usam
+// o codec JSON ou XML, e text/csv usa o codec CSV quando nenhum outro foi
+// registrado. Retorna nil se nenhum codec for encontrado.
+func (r *codecRegistry) lookup(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c, ok := r.codecs[mediaType]; ok {
+		return c
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return r.codecs["application/json"]
+	case strings.HasSuffix(mediaType, "+xml"), mediaType == "text/xml":
+		return r.codecs["application/xml"]
+	case mediaType == "text/csv":
+		return csvCodec{}
+	}
+	return nil
+}
+
+// accept monta o valor do header Accept a partir dos codecs registrados,
+// em ordem decrescente de preferência.
+func (r *codecRegistry) accept() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	values := make([]string, 0, len(r.order))
+	for i, mediaType := range r.order {
+		q := 10 - i
+		if i == 0 {
+			values = append(values, mediaType)
+			continue
+		}
+		if q < 1 {
+			q = 1
+		}
+		values = append(values, fmt.Sprintf("%s;q=0.%d", mediaType, q))
+	}
+	return strings.Join(values, ", ")
+}
+
+// withCharset adiciona charset=utf-8 aos media types textuais (JSON, XML e text/*).
+func withCharset(mediaType string) string {
+	switch {
+	case mediaType == "application/json", mediaType == "application/xml",
+		strings.HasPrefix(mediaType, "text/"):
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
+
+// jsonCodec é o codec de application/json.
+type jsonCodec struct{}
+
+// MediaType retorna "application/json".
+func (jsonCodec) MediaType() string { return "application/json" }
+
+// Encode codifica v em JSON.
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode decodifica JSON em v.
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// xmlCodec é o codec de application/xml.
+type xmlCodec struct{}
+
+// MediaType retorna "application/xml".
+func (xmlCodec) MediaType() string { return "application/xml" }
+
+// Encode codifica v em XML.
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode decodifica XML em v.
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// formCodec é o codec de application/x-www-form-urlencoded.
+// Codifica url.Values, map[string]string e map[string][]string, e decodifica
+// em *url.Values, *map[string]string ou *map[string][]string.
+type formCodec struct{}
+
+// MediaType retorna "application/x-www-form-urlencoded".
+func (formCodec) MediaType() string { return "application/x-www-form-urlencoded" }
+
+// Encode codifica v como formulário URL-encoded.
+func (formCodec) Encode(w io.Writer, v interface{}) error {
+	values := url.Values{}
+	switch form := v.(type) {
+	case url.Values:
+		values = form
+	case map[string][]string:
+		values = form
+	case map[string]string:
+		for key, value := range form {
+			values.Set(key, value)
+		}
+	default:
+		return fmt.Errorf("lapi: tipo %T não pode ser codificado como formulário", v)
+	}
+	_, err := io.WriteString(w, values.Encode())
+	return err
+}
+
+// Decode decodifica um formulário URL-encoded em v.
+func (formCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch dest := v.(type) {
+	case *url.Values:
+		*dest = values
+	case *map[string][]string:
+		*dest = values
+	case *map[string]string:
+		*dest = make(map[string]string, len(values))
+		for key := range values {
+			(*dest)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("lapi: formulário não pode ser decodificado em %T", v)
+	}
+	return nil
+}
+
+// textCodec é o codec de text/plain.
+// Codifica string, []byte e fmt.Stringer, e decodifica em *string ou *[]byte.
+type textCodec struct{}
+
+// MediaType retorna "text/plain".
+func (textCodec) MediaType() string { return "text/plain" }
+
+// Encode escreve v como texto.
+func (textCodec) Encode(w io.Writer, v interface{}) error {
+	var err error
+	switch text := v.(type) {
+	case string:
+		_, err = io.WriteString(w, text)
+	case []byte:
+		_, err = w.Write(text)
+	case fmt.Stringer:
+		_, err = io.WriteString(w, text.String())
+	default:
+		_, err = fmt.Fprint(w, v)
+	}
+	return err
+}
+
+// Decode lê o texto de r para v.
+func (textCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch dest := v.(type) {
+	case *string:
+		*dest = string(data)
+	case *[]byte:
+		*dest = data
+	default:
+		return fmt.Errorf("lapi: texto não pode ser decodificado em %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package lapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+)
+
+// Compressor é uma interface que representa um algoritmo de compressão
+// do corpo das requisições (Content-Encoding).
+//
+// Exemplo de uso:
+//
+//	m.SetCompression(NewGzipCompressor(gzip.BestSpeed), 1024)
+type Compressor interface {
+	// Encoding retorna o valor do header Content-Encoding (ex: "gzip").
+	Encoding() string
+
+	// NewWriter retorna um writer que comprime os dados escritos em w.
+	// O writer é fechado ao final do corpo.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// gzipCompressor é uma implementação de Compressor para gzip.
+type gzipCompressor struct {
+	level int
+}
+
+// NewGzipCompressor cria um Compressor gzip.
+//
+// Parâmetros:
+//   - level: Nível de compressão (ex: gzip.DefaultCompression, gzip.BestSpeed)
+//
+// Exemplo:
+//
+//	m.SetCompression(NewGzipCompressor(gzip.DefaultCompression), 1024)
+func NewGzipCompressor(level int) *gzipCompressor {
+	return &gzipCompressor{level: level}
+}
+
+// Encoding retorna "gzip".
+func (c *gzipCompressor) Encoding() string { return "gzip" }
+
+// NewWriter retorna um gzip.Writer sobre w.
+func (c *gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+// deflateCompressor é uma implementação de Compressor para deflate
+// (formato zlib, conforme o Content-Encoding HTTP).
+type deflateCompressor struct {
+	level int
+}
+
+// NewDeflateCompressor cria um Compressor deflate.
+//
+// Parâmetros:
+//   - level: Nível de compressão (ex: zlib.DefaultCompression, zlib.BestSpeed)
+//
+// Exemplo:
+//
+//	m.SetCompression(NewDeflateCompressor(zlib.DefaultCompression), 1024)
+func NewDeflateCompressor(level int) *deflateCompressor {
+	return &deflateCompressor{level: level}
+}
+
+// Encoding retorna "deflate".
+func (c *deflateCompressor) Encoding() string { return "deflate" }
+
+// NewWriter retorna um zlib.Writer sobre w.
+func (c *deflateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriterLevel(w, c.level)
+}
+
+// SetCompression define a compressão do corpo das requisições.
+// Apenas corpos com pelo menos threshold bytes são comprimidos.
+//
+// Parâmetros:
+//   - c: Implementação de Compressor (ex: NewGzipCompressor); nil desativa a compressão
+//   - threshold: Tamanho mínimo, em bytes, do corpo a ser comprimido
+//
+// Exemplo:
+//
+//	r.SetCompression(NewGzipCompressor(gzip.DefaultCompression), 1024)
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetCompression(c Compressor, threshold int64) *request {
+	r.compressor = c
+	r.compressThreshold = threshold
+	return r
+}
+
+// SetCompression define a compressão do corpo das requisições do modelo.
+// Apenas corpos com pelo menos threshold bytes são comprimidos.
+//
+// Parâmetros:
+//   - c: Implementação de Compressor (ex: NewGzipCompressor); nil desativa a compressão
+//   - threshold: Tamanho mínimo, em bytes, do corpo a ser comprimido
+//
+// Exemplo:
+//
+//	m.SetCompression(NewGzipCompressor(gzip.BestSpeed), 4096)
+func (m *model) SetCompression(c Compressor, threshold int64) {
+	m.request.SetCompression(c, threshold)
+}
+
+// compressBody comprime o corpo de req e define o header Content-Encoding.
+// Corpos de tamanho conhecido e reenviáveis são comprimidos em memória, mantendo
+// o Content-Length. Os demais (ex: SetBody com io.Reader, multipart) são comprimidos
+// sob demanda; neste caso, os primeiros threshold bytes são lidos para decidir se
+// o corpo deve ser comprimido. req.GetBody é ajustado para recriar o corpo comprimido.
+func compressBody(req *http.Request, c Compressor, threshold int64) error {
+	if c == nil || req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	if req.ContentLength > 0 && req.ContentLength < threshold {
+		return nil
+	}
+
+	// Known size: compress in memory
+	if req.ContentLength > 0 && req.GetBody != nil {
+		var buf bytes.Buffer
+		err := compressTo(&buf, c, req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		data := buf.Bytes()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Encoding", c.Encoding())
+		return nil
+	}
+
+	// Unknown size: peek the threshold and compress while sending
+	prefix := make([]byte, threshold)
+	n, err := io.ReadFull(req.Body, prefix)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		req.Body = readCloser{bytes.NewReader(prefix[:n]), req.Body}
+		req.ContentLength = int64(n)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	req.Body = compressStream(c, readCloser{io.MultiReader(bytes.NewReader(prefix), req.Body), req.Body})
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return compressStream(c, body), nil
+		}
+	}
+	req.ContentLength = -1
+	req.Header.Set("Content-Encoding", c.Encoding())
+	return nil
+}
+
+// compressStream retorna um leitor com o conteúdo de body comprimido sob demanda.
+func compressStream(c Compressor, body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		err := compressTo(pw, c, body)
+		body.Close()
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// compressTo comprime o conteúdo de src em dst.
+func compressTo(dst io.Writer, c Compressor, src io.Reader) error {
+	w, err := c.NewWriter(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// readCloser combina um io.Reader com o io.Closer do corpo original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -2,9 +2,7 @@ package lapi
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -40,6 +38,13 @@ type model struct {
 	// inDevelopment indica se a requisição está em ambiente de desenvolvimento.
 	// Quando true, logs adicionais podem ser exibidos para debug.
 	inDevelopment bool
+
+	// tokenStore é o armazenamento persistente dos tokens de autenticação.
+	// Quando definido, os tokens são salvos sempre que forem alterados ou renovados.
+	tokenStore TokenStore
+
+	// refresh é a função usada para renovar o token de acesso.
+	refresh RefreshFunc
 }
 
 // NewRequest cria uma nova instância de Model com as configurações especificadas.
@@ -54,37 +59,118 @@ type model struct {
 //	m := NewRequest("https://api.exemplo.com", map[string]string{
 //	    "Content-Type": "application/json",
 //	}, 30)
+//
+// Se a URL base ou os headers forem inválidos, o erro é registrado e
+// retornado pelas chamadas, antes de qualquer I/O de rede.
 func NewRequest(baseURL string, headers map[string]string, timeout int) *model {
-	return &model{
+	m := &model{
 		request: &request{
-			baseURL: baseURL,
-			headers: headers,
+			headers: make(map[string]string),
 			method:  "GET",
 			timeout: time.Duration(timeout) * time.Second,
 			query:   make(url.Values),
 		},
 	}
+	m.request.SetBaseURL(baseURL)
+	if headers != nil {
+		m.request.SetHeaders(headers)
+	}
+	return m
+}
+
+// SetDevelopment define se o modelo está em ambiente de desenvolvimento.
+// Em desenvolvimento, configurações inseguras como TLSConfig.InsecureSkipVerify são permitidas.
+//
+// Exemplo:
+//
+//	m.SetDevelopment(os.Getenv("APP_ENV") == "development")
+func (m *model) SetDevelopment(enabled bool) {
+	m.inDevelopment = enabled
 }
 
 // MakeRequest executa uma requisição HTTP com os parâmetros especificados.
+// O payload é codificado pelo codec do media type escolhido (ver WithContentType)
+// e a resposta é decodificada pelo codec do Content-Type retornado pelo servidor.
+// Quando dest é *[]byte, o corpo da resposta é copiado sem decodificação.
 //
 // Parâmetros:
 //   - method: Método HTTP (GET, POST, PUT, DELETE, etc)
 //   - path: Caminho do endpoint (ex: "/users")
 //   - payload: Dados a serem enviados no corpo da requisição (opcional)
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Retorna:
 //   - *httpError: Erro HTTP se a requisição falhar, nil caso contrário
-func (m *model) MakeRequest(method string, path string, payload *interface{}, dest interface{}) *httpError {
+func (m *model) MakeRequest(method string, path string, payload *interface{}, dest interface{}, opts ...CallOption) *httpError {
+	options := newCallOptions(opts)
+	resp, httpErr := m.exchange(method, path, payload, options)
+	if httpErr != nil {
+		return httpErr
+	}
+	defer resp.Body.Close()
+
+	// Parse response
+	limit := options.bodyLimit(m.request.maxBodySize)
+	if limit > 0 && resp.ContentLength > limit {
+		return m.wrapError(http.StatusInternalServerError, &BodyTooLargeError{Limit: limit}, "Houve um erro interno no servidor! C: 04")
+	}
+
+	download := newProgressTracker(options.download)
+	defer download.finish()
+
+	body := &readErrRecorder{r: limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), limit)}
+	if err := decodeBody(resp.Header.Get("Content-Type"), body, dest, options); err != nil {
+		if body.err != nil {
+			return m.wrapError(http.StatusInternalServerError, body.err, "Houve um erro interno no servidor! C: 04")
+		}
+		if options.disallowUnknownFields {
+			return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 08")
+		}
+		log.Println(err.Error())
+	}
+
+	// Check status code
+	if resp.StatusCode >= 400 {
+		return m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+
+	return nil
+}
+
+// exchange monta e envia a requisição HTTP de uma chamada do modelo, com o
+// corpo, os headers, a compressão e a autenticação configurados.
+// O chamador é responsável por fechar o corpo da resposta.
+func (m *model) exchange(method string, path string, payload *interface{}, options *callOptions) (*http.Response, *httpError) {
+	if err := m.request.Err(); err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Requisição inválida: "+err.Error())
+	}
 	m.request.method = method
 	// Parse the body
-	if payload != nil {
-		bodyJson, err := json.Marshal(payload)
-		if err != nil {
-			return m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 01")
+	reqBody, source, contentType := m.request.body, m.request.source, m.request.contentType
+	if options.multipart != nil {
+		if err := options.multipart.Err(); err != nil {
+			return nil, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 06")
+		}
+		reqBody, source = nil, options.multipart.source()
+	} else if payload != nil {
+		mediaType := options.contentType
+		if mediaType == "" {
+			mediaType = headerValue(m.request.headers, "Content-Type")
+		}
+		codec := codecs.lookup(mediaType)
+		if codec == nil {
+			if options.contentType != "" {
+				return nil, m.MakeError(http.StatusInternalServerError, "codec not found: "+options.contentType, "Houve um erro interno no servidor! C: 05")
+			}
+			codec = jsonCodec{}
+		}
+
+		encoded := &bytes.Buffer{}
+		if err := codec.Encode(encoded, *payload); err != nil {
+			return nil, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 01")
 		}
-		m.request.body = bytes.NewBuffer(bodyJson)
+		reqBody, source, contentType = encoded, nil, withCharset(codec.MediaType())
 	}
 
 	// Parse the query
@@ -96,71 +182,104 @@ func (m *model) MakeRequest(method string, path string, payload *interface{}, de
 	}
 
 	// Make the request
-	req, err := http.NewRequest(
+	req, err := newHTTPRequest(
 		m.request.method,
 		fmt.Sprintf("%s%s%s%s", m.request.baseURL, path, qp, m.request.query.Encode()),
-		m.request.body,
+		reqBody,
+		source,
 	)
 	if err != nil {
-		return m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 02")
+		return nil, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 02")
 	}
 
 	// Parse the headers
-	for k, v := range m.request.headers {
-		req.Header.Add(k, v)
+	setHeaders(req, m.request.headers, contentType)
+	if options.contentType != "" && payload != nil {
+		req.Header.Set("Content-Type", options.contentType)
+	}
+	if source != nil && source.contentType != "" {
+		req.Header.Set("Content-Type", source.contentType)
+	}
+	for key, value := range options.headers {
+		req.Header.Set(key, value)
+	}
+	if err := compressBody(req, m.request.compressor, m.request.compressThreshold); err != nil {
+		return nil, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 07")
 	}
 
-	// Parse the auth
-	if m.Auth.Token != "" {
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", m.Auth.Token))
+	if options.ctx != nil {
+		req = req.WithContext(options.ctx)
+	}
+	if options.stream {
+		req = withStreaming(req)
 	}
 
 	// Perform request
-	client := &http.Client{
-		Timeout: m.request.timeout,
+	upload := newProgressTracker(options.upload)
+	resp, err := m.send(withUploadTracker(req, upload))
+	upload.finish()
+	if err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 03")
 	}
-	start := time.Now()
-	resp, err := client.Do(req)
-
-	responseTime := time.Since(start).Milliseconds()
-	status := "(408 timeout)"
+	return resp, nil
+}
 
-	if resp != nil {
-		status = resp.Status
+// headerValue retorna o valor de um header do mapa, ignorando maiúsculas e minúsculas.
+func headerValue(headers map[string]string, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
 	}
+	return ""
+}
 
-	// Log request
-	logMsg := fmt.Sprintf("[%s] %s (%s) %d ms", req.Method, req.URL, status, responseTime)
-	log.Println(logMsg)
-
-	if err != nil {
-		return m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 03")
+// send executa a requisição HTTP e, quando o servidor responde 401 e existe
+// uma RefreshFunc configurada, renova o token e repete a requisição uma única vez.
+func (m *model) send(req *http.Request) (*http.Response, error) {
+	resp, err := m.do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || m.refresh == nil {
+		return resp, err
 	}
-	defer resp.Body.Close()
 
-	// Parse response
-	body, err := io.ReadAll(resp.Body)
+	retry, err := cloneRequest(req)
 	if err != nil {
-		return m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 04")
+		return resp, nil
 	}
 
-	if resp.Header.Get("Content-Type") == "application/pdf" {
-		*dest.(*[]byte) = body
-		return nil
+	previous := m.Auth.Token
+	if m.RevalidateToken() == previous {
+		return resp, nil
 	}
+	resp.Body.Close()
 
-	if err := json.Unmarshal(body, dest); err != nil {
-		fmt.Println(string(body))
-		log.Println(err.Error())
-		return nil
+	return m.do(retry)
+}
+
+// do adiciona a autenticação à requisição, executa-a e registra o resultado no log.
+// Quando nenhum Authenticator estiver configurado, o token de acesso é enviado como Bearer.
+func (m *model) do(req *http.Request) (*http.Response, error) {
+	// Parse the auth
+	if m.request.auth == nil && m.Auth.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Auth.Token))
 	}
 
-	// Check status code
-	if resp.StatusCode >= 400 {
-		return m.MakeError(resp.StatusCode, string(body), "Status code >= 400")
+	// Perform request
+	start := time.Now()
+	resp, err := m.request.roundTrip(req)
+
+	responseTime := time.Since(start).Milliseconds()
+	status := "(408 timeout)"
+
+	if resp != nil {
+		status = resp.Status
 	}
 
-	return nil
+	// Log request
+	logMsg := fmt.Sprintf("[%s] %s (%s) %d ms", req.Method, req.URL, status, responseTime)
+	log.Println(logMsg)
+
+	return resp, err
 }
 
 // Request retorna a instância da requisição HTTP associada ao modelo.
@@ -173,13 +292,14 @@ func (m *model) Request() *request {
 // Parâmetros:
 //   - path: Caminho do endpoint (ex: "/users")
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Exemplo:
 //
 //	var response Response
 //	err := m.Get("/users", &response)
-func (m *model) Get(path string, dest interface{}) *httpError {
-	r := m.MakeRequest("GET", path, nil, dest)
+func (m *model) Get(path string, dest interface{}, opts ...CallOption) *httpError {
+	r := m.MakeRequest("GET", path, nil, dest, opts...)
 	return r
 }
 
@@ -189,14 +309,15 @@ func (m *model) Get(path string, dest interface{}) *httpError {
 //   - path: Caminho do endpoint (ex: "/users")
 //   - payload: Dados a serem enviados no corpo da requisição
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Exemplo:
 //
 //	payload := map[string]string{"name": "John"}
 //	var response Response
 //	err := m.Post("/users", &payload, &response)
-func (m *model) Post(path string, payload *interface{}, dest interface{}) *httpError {
-	r := m.MakeRequest("POST", path, payload, dest)
+func (m *model) Post(path string, payload *interface{}, dest interface{}, opts ...CallOption) *httpError {
+	r := m.MakeRequest("POST", path, payload, dest, opts...)
 	return r
 }
 
@@ -206,14 +327,15 @@ func (m *model) Post(path string, payload *interface{}, dest interface{}) *httpE
 //   - path: Caminho do endpoint (ex: "/users/1")
 //   - payload: Dados a serem enviados no corpo da requisição
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Exemplo:
 //
 //	payload := map[string]string{"name": "John"}
 //	var response Response
 //	err := m.Put("/users/1", &payload, &response)
-func (m *model) Put(path string, payload *interface{}, dest interface{}) *httpError {
-	r := m.MakeRequest("PUT", path, payload, dest)
+func (m *model) Put(path string, payload *interface{}, dest interface{}, opts ...CallOption) *httpError {
+	r := m.MakeRequest("PUT", path, payload, dest, opts...)
 	return r
 }
 
@@ -222,13 +344,14 @@ func (m *model) Put(path string, payload *interface{}, dest interface{}) *httpEr
 // Parâmetros:
 //   - path: Caminho do endpoint (ex: "/users/1")
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Exemplo:
 //
 //	var response Response
 //	err := m.Delete("/users/1", &response)
-func (m *model) Delete(path string, dest interface{}) *httpError {
-	r := m.MakeRequest("DELETE", path, nil, dest)
+func (m *model) Delete(path string, dest interface{}, opts ...CallOption) *httpError {
+	r := m.MakeRequest("DELETE", path, nil, dest, opts...)
 	return r
 }
 
@@ -238,13 +361,14 @@ func (m *model) Delete(path string, dest interface{}) *httpError {
 //   - path: Caminho do endpoint (ex: "/users/1")
 //   - payload: Dados a serem enviados no corpo da requisição
 //   - dest: Ponteiro para a estrutura que receberá a resposta
+//   - opts: Opções da chamada (opcional)
 //
 // Exemplo:
 //
 //	payload := map[string]string{"name": "John"}
 //	var response Response
 //	err := m.Patch("/users/1", &payload, &response)
-func (m *model) Patch(path string, payload *interface{}, dest interface{}) *httpError {
-	r := m.MakeRequest("PATCH", path, payload, dest)
+func (m *model) Patch(path string, payload *interface{}, dest interface{}, opts ...CallOption) *httpError {
+	r := m.MakeRequest("PATCH", path, payload, dest, opts...)
 	return r
 }
@@ -0,0 +1,620 @@
+package lapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithCSVDelimiter define o separador das colunas CSV (ex: ';').
+// Por padrão, o separador é detectado no cabeçalho entre ',', ';', tab e '|'.
+//
+// Exemplo:
+//
+//	var rows []Row
+//	err := m.Get("/dados.csv", &rows, WithCSVDelimiter(';'))
+func WithCSVDelimiter(delimiter rune) CallOption {
+	return func(o *callOptions) {
+		o.csvDelimiter = delimiter
+	}
+}
+
+// CSV faz uma requisição GET para path e decodifica a resposta CSV linha a linha,
+// sem carregá-la em memória. A primeira linha é o cabeçalho; as colunas são associadas
+// aos campos de T pela tag `csv:"coluna"` ou, sem tag, pelo nome do campo
+// (ignorando maiúsculas e minúsculas).
+//
+// Uma linha que não pode ser convertida é entregue como erro, com o número da linha,
+// e a iteração continua; erros de status ou de leitura encerram a iteração.
+// Interromper o laço fecha a conexão. O timeout do cliente não se aplica; use
+// WithContext para limitar a duração.
+//
+// Parâmetros:
+//   - m: Modelo com a configuração do cliente
+//   - path: Caminho do endpoint (ex: "/relatorios/vendas.csv")
+//   - opts: Opções da chamada (ex: WithCSVDelimiter, WithDisallowUnknownFields)
+//
+// Exemplo:
+//
+//	type Venda struct {
+//	    Data  time.Time `csv:"data,layout=02/01/2006"`
+//	    UF    string    `csv:"uf"`
+//	    Valor float64   `csv:"valor"`
+//	}
+//
+//	for venda, err := range CSV[Venda](m, "/vendas.csv", WithCSVDelimiter(';')) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    total += venda.Valor
+//	}
+func CSV[T any](m *model, path string, opts ...CallOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		options := newCallOptions(append([]CallOption{WithHeader("Accept", "text/csv")}, opts...))
+		options.stream = true
+		resp, httpErr := m.exchange(http.MethodGet, path, nil, options)
+		if httpErr != nil {
+			yield(zero, httpErr)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			yield(zero, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400"))
+			return
+		}
+
+		download := newProgressTracker(options.download)
+		defer download.finish()
+
+		body := limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), options.bodyLimit(m.request.maxBodySize))
+		decoder, err := newCSVDecoder(body, options.csvDelimiter)
+		if err != nil {
+			if err != io.EOF {
+				yield(zero, err)
+			}
+			return
+		}
+		mapping, err := newCSVMapping(reflect.TypeFor[T](), decoder.header, options.disallowUnknownFields)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for {
+			record, err := decoder.reader.Read()
+			if err == io.EOF {
+				return
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				if !yield(zero, fmt.Errorf("lapi: csv: %w", err)) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var item T
+			if err := mapping.decode(record, reflect.ValueOf(&item).Elem()); err != nil {
+				line, _ := decoder.reader.FieldPos(0)
+				if !yield(zero, fmt.Errorf("lapi: linha %d: %w", line, err)) {
+					return
+				}
+				continue
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// csvCodec é o codec de text/csv. Decodifica em *[]T (structs, ponteiros para
+// structs ou map[string]string) usando o cabeçalho, ou em *[][]string sem
+// interpretar o cabeçalho. Codifica slices de structs com uma linha de cabeçalho.
+type csvCodec struct {
+	// delimiter é o separador das colunas; zero detecta no cabeçalho.
+	delimiter rune
+
+	// strict faz colunas sem campo correspondente gerarem erro.
+	strict bool
+}
+
+// MediaType retorna "text/csv".
+func (csvCodec) MediaType() string { return "text/csv" }
+
+// withOptions retorna o codec com o separador e o modo estrito da chamada.
+func (c csvCodec) withOptions(options *callOptions) csvCodec {
+	if options.csvDelimiter != 0 {
+		c.delimiter = options.csvDelimiter
+	}
+	c.strict = options.disallowUnknownFields
+	return c
+}
+
+// Encode escreve v (slice de structs ou [][]string) como CSV.
+func (c csvCodec) Encode(w io.Writer, v interface{}) error {
+	writer := csv.NewWriter(w)
+	if c.delimiter != 0 {
+		writer.Comma = c.delimiter
+	}
+
+	if records, ok := v.([][]string); ok {
+		return writer.WriteAll(records)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("lapi: %T não pode ser codificado como CSV", v)
+	}
+	elem := value.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("lapi: %T não pode ser codificado como CSV", v)
+	}
+
+	fields := csvFields(elem)
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := 0; i < value.Len(); i++ {
+		item := reflect.Indirect(value.Index(i))
+		for j, field := range fields {
+			record[j] = ""
+			if item.IsValid() {
+				text, err := formatCSVValue(item.FieldByIndex(field.index), field.layout)
+				if err != nil {
+					return fmt.Errorf("lapi: csv coluna %q: %w", field.name, err)
+				}
+				record[j] = text
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Decode lê o CSV de r para v.
+func (c csvCodec) Decode(r io.Reader, v interface{}) error {
+	decoder, err := newCSVDecoder(r, c.delimiter)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if dest, ok := v.(*[][]string); ok {
+		records := [][]string{decoder.header}
+		decoder.reader.ReuseRecord = false
+		for {
+			record, err := decoder.reader.Read()
+			if err == io.EOF {
+				*dest = records
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+	}
+
+	dest := reflect.ValueOf(v)
+	if dest.Kind() != reflect.Pointer || dest.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("lapi: CSV não pode ser decodificado em %T", v)
+	}
+	slice := dest.Elem()
+	mapping, err := newCSVMapping(slice.Type().Elem(), decoder.header, c.strict)
+	if err != nil {
+		return err
+	}
+
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+	for {
+		record, err := decoder.reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("lapi: csv: %w", err)
+		}
+		item := reflect.New(slice.Type().Elem()).Elem()
+		if err := mapping.decode(record, item); err != nil {
+			line, _ := decoder.reader.FieldPos(0)
+			return fmt.Errorf("lapi: linha %d: %w", line, err)
+		}
+		rows = reflect.Append(rows, item)
+	}
+	slice.Set(rows)
+	return nil
+}
+
+// csvDecoder lê um CSV com cabeçalho.
+type csvDecoder struct {
+	reader *csv.Reader
+	header []string
+}
+
+// newCSVDecoder lê o cabeçalho de r, detectando o separador quando delimiter é zero.
+// Retorna io.EOF quando o conteúdo está vazio.
+func newCSVDecoder(r io.Reader, delimiter rune) (*csvDecoder, error) {
+	buffered := bufio.NewReaderSize(r, 64<<10)
+	if bom, _ := buffered.Peek(3); bytes.Equal(bom, []byte("\ufeff")) {
+		buffered.Discard(3)
+	}
+	if delimiter == 0 {
+		delimiter = detectCSVDelimiter(buffered)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(header))
+	for i, name := range header {
+		names[i] = strings.TrimSpace(name)
+	}
+	return &csvDecoder{reader: reader, header: names}, nil
+}
+
+// detectCSVDelimiter escolhe o separador mais frequente na primeira linha,
+// ignorando os trechos entre aspas. Lê do stream apenas o necessário para
+// obter a primeira linha, sem esperar o buffer inteiro.
+func detectCSVDelimiter(r *bufio.Reader) rune {
+	candidates := []rune{',', ';', '\t', '|'}
+	counts := make([]int, len(candidates))
+	quoted := false
+
+	for pos := 0; ; {
+		data, _ := r.Peek(r.Buffered())
+		for ; pos < len(data); pos++ {
+			c := data[pos]
+			switch {
+			case c == '"':
+				quoted = !quoted
+			case quoted:
+			case c == '\n':
+				return bestCSVDelimiter(candidates, counts)
+			default:
+				for i, candidate := range candidates {
+					if rune(c) == candidate {
+						counts[i]++
+					}
+				}
+			}
+		}
+		// Wait for one more read; stops on EOF or when the buffer is full
+		if _, err := r.Peek(r.Buffered() + 1); err != nil {
+			return bestCSVDelimiter(candidates, counts)
+		}
+	}
+}
+
+// bestCSVDelimiter retorna o candidato com mais ocorrências, ou vírgula se nenhum aparecer.
+func bestCSVDelimiter(candidates []rune, counts []int) rune {
+	best, count := ',', 0
+	for i, candidate := range candidates {
+		if counts[i] > count {
+			best, count = candidate, counts[i]
+		}
+	}
+	return best
+}
+
+// csvField é um campo de struct associado a uma coluna.
+type csvField struct {
+	name   string
+	index  []int
+	layout string
+}
+
+// csvFields lista os campos exportados da struct com o nome da coluna e o layout de data.
+// Campos com a tag `csv:"-"` são ignorados.
+func csvFields(t reflect.Type) []csvField {
+	var fields []csvField
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || embeddedPointer(t, field.Index) {
+			continue
+		}
+		tag, hasTag := field.Tag.Lookup("csv")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasTag {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		layout := ""
+		for _, option := range strings.Split(options, ",") {
+			if value, ok := strings.CutPrefix(option, "layout="); ok {
+				layout = value
+			}
+		}
+		fields = append(fields, csvField{name: name, index: field.Index, layout: layout})
+	}
+	return fields
+}
+
+// embeddedPointer indica se o caminho do campo passa por uma struct embutida por ponteiro.
+func embeddedPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Pointer {
+			return true
+		}
+		t = field.Type
+	}
+	return false
+}
+
+// csvMapping associa as colunas do cabeçalho aos campos do tipo de destino.
+type csvMapping struct {
+	header  []string
+	columns []*csvField
+	isMap   bool
+	pointer bool
+}
+
+// newCSVMapping associa o cabeçalho aos campos de t (struct, ponteiro para struct
+// ou map[string]string). Com strict, colunas sem campo correspondente são um erro.
+func newCSVMapping(t reflect.Type, header []string, strict bool) (*csvMapping, error) {
+	mapping := &csvMapping{header: append([]string(nil), header...)}
+	if t.Kind() == reflect.Pointer {
+		mapping.pointer = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+		mapping.isMap = true
+		return mapping, nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("lapi: CSV não pode ser decodificado em %s", t)
+	}
+
+	fields := csvFields(t)
+	mapping.columns = make([]*csvField, len(header))
+	for i, name := range header {
+		for j := range fields {
+			if strings.EqualFold(fields[j].name, name) {
+				mapping.columns[i] = &fields[j]
+				break
+			}
+		}
+		if mapping.columns[i] == nil && strict {
+			return nil, fmt.Errorf("lapi: coluna CSV %q sem campo correspondente em %s", name, t)
+		}
+	}
+	return mapping, nil
+}
+
+// decode converte uma linha em item. Colunas ausentes na linha ficam com o valor zero.
+func (mp *csvMapping) decode(record []string, item reflect.Value) error {
+	if mp.pointer {
+		item.Set(reflect.New(item.Type().Elem()))
+		item = item.Elem()
+	}
+
+	if mp.isMap {
+		row := reflect.MakeMapWithSize(item.Type(), len(mp.header))
+		for i, name := range mp.header {
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			row.SetMapIndex(reflect.ValueOf(name).Convert(item.Type().Key()), reflect.ValueOf(value).Convert(item.Type().Elem()))
+		}
+		item.Set(row)
+		return nil
+	}
+
+	for i, field := range mp.columns {
+		if field == nil || i >= len(record) {
+			continue
+		}
+		if err := parseCSVValue(item.FieldByIndex(field.index), record[i], field.layout); err != nil {
+			return fmt.Errorf("coluna %q: %w", mp.header[i], err)
+		}
+	}
+	return nil
+}
+
+// csvTimeLayouts são os formatos de data tentados quando a tag não informa layout.
+var csvTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+}
+
+// timeType e durationType são os tipos de data e duração tratados de forma especial.
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// parseCSVValue converte o texto de uma coluna para o tipo do campo.
+// Valores vazios mantêm o valor zero (ponteiros ficam nil).
+func parseCSVValue(field reflect.Value, text, layout string) error {
+	if field.Kind() != reflect.String {
+		text = strings.TrimSpace(text)
+	}
+	if text == "" {
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := parseCSVValue(value.Elem(), text, layout); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	switch field.Type() {
+	case timeType:
+		layouts := csvTimeLayouts
+		if layout != "" {
+			layouts = []string{layout}
+		}
+		for _, l := range layouts {
+			if t, err := time.Parse(l, text); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("data inválida: %q", text)
+	case durationType:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := parseCSVBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseCSVFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("tipo %s não suportado", field.Type())
+	}
+	return nil
+}
+
+// parseCSVBool aceita os formatos de strconv.ParseBool e sim/não, s/n, yes/no e y/n.
+func parseCSVBool(text string) (bool, error) {
+	switch strings.ToLower(text) {
+	case "sim", "s", "yes", "y":
+		return true, nil
+	case "não", "nao", "n", "no":
+		return false, nil
+	}
+	return strconv.ParseBool(text)
+}
+
+// csvDecimalComma reconhece números no formato brasileiro, com vírgula decimal e,
+// opcionalmente, ponto como separador de milhar em grupos de três dígitos.
+var csvDecimalComma = regexp.MustCompile(`^[-+]?(\d{1,3}(\.\d{3})*|\d+),\d+$`)
+
+// parseCSVFloat converte um número, aceitando também o formato brasileiro
+// (ex: "1.234,56"). Outros formatos com vírgula (ex: "1,234.56") são rejeitados.
+func parseCSVFloat(text string, bits int) (float64, error) {
+	f, err := strconv.ParseFloat(text, bits)
+	if err == nil || !csvDecimalComma.MatchString(text) {
+		return f, err
+	}
+	converted := strings.ReplaceAll(strings.ReplaceAll(text, ".", ""), ",", ".")
+	return strconv.ParseFloat(converted, bits)
+}
+
+// formatCSVValue converte o valor de um campo em texto.
+func formatCSVValue(value reflect.Value, layout string) (string, error) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Type() {
+	case timeType:
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return t.Format(layout), nil
+	case durationType:
+		return time.Duration(value.Int()).String(), nil
+	}
+
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("tipo %s não suportado", value.Type())
+}
@@ -0,0 +1,62 @@
+package lapi
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want rune
+	}{
+		{"vírgula", "nome,valor,pago\nAna,10,sim\n", ','},
+		{"ponto e vírgula", "nome;valor;pago\nAna;1.234,56;sim\n", ';'},
+		{"tab", "nome\tvalor\n", '\t'},
+		{"pipe", "nome|valor|pago", '|'},
+		{"vírgulas entre aspas", `"Silva, Ana";"1,5";"2,5"` + "\n", ';'},
+		{"quebra de linha entre aspas", "\"a\nb,c,d,e\";x;y\n", ';'},
+		{"sem separador", "nome\n", ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCSVDelimiter(bufio.NewReader(strings.NewReader(tt.data))); got != tt.want {
+				t.Errorf("detectCSVDelimiter(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCSVDelimiterDoesNotWaitForBuffer(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte("nome;valor\nAna;"))
+
+	done := make(chan rune, 1)
+	go func() {
+		done <- detectCSVDelimiter(bufio.NewReaderSize(pr, 64<<10))
+	}()
+
+	select {
+	case got := <-done:
+		if got != ';' {
+			t.Errorf("separador = %q, want ';'", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("detectCSVDelimiter aguardou o buffer encher com a primeira linha já disponível")
+	}
+}
+
+func TestCSVCodecNotInDefaultAccept(t *testing.T) {
+	if accept := codecs.accept(); strings.Contains(accept, "text/csv") {
+		t.Errorf("Accept padrão = %q, não deveria conter text/csv", accept)
+	}
+	if _, ok := codecs.lookup("text/csv; charset=utf-8").(csvCodec); !ok {
+		t.Error("lookup(text/csv) deveria retornar o codec CSV")
+	}
+}
@@ -0,0 +1,198 @@
+package lapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge indica que o corpo da resposta ultrapassou o tamanho máximo configurado.
+// Os erros do tipo *BodyTooLargeError correspondem a ErrBodyTooLarge em errors.Is.
+//
+// Exemplo de uso:
+//
+//	if err := m.Get("/export", &dest); err != nil && errors.Is(err, ErrBodyTooLarge) {
+//	    // ...
+//	}
+var ErrBodyTooLarge = errors.New("lapi: corpo da resposta excede o tamanho máximo")
+
+// BodyTooLargeError é o erro retornado quando o corpo da resposta ultrapassa o limite.
+type BodyTooLargeError struct {
+	// Limit é o tamanho máximo, em bytes, configurado.
+	Limit int64
+}
+
+// Error implementa a interface error do Go.
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("lapi: corpo da resposta excede o tamanho máximo de %d bytes", e.Limit)
+}
+
+// Is permite o uso de errors.Is(err, ErrBodyTooLarge).
+func (e *BodyTooLargeError) Is(target error) bool {
+	return target == ErrBodyTooLarge
+}
+
+// SetMaxBodySize define o tamanho máximo, em bytes, do corpo das respostas.
+// Zero (padrão) não limita o tamanho.
+//
+// Parâmetros:
+//   - size: Tamanho máximo em bytes
+//
+// Exemplo:
+//
+//	r.SetMaxBodySize(10 << 20) // 10 MiB
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetMaxBodySize(size int64) *request {
+	r.maxBodySize = size
+	return r
+}
+
+// SetMaxBodySize define o tamanho máximo, em bytes, do corpo das respostas do modelo.
+// Zero (padrão) não limita o tamanho. Pode ser alterado por chamada com WithMaxBodySize.
+//
+// Parâmetros:
+//   - size: Tamanho máximo em bytes
+//
+// Exemplo:
+//
+//	m.SetMaxBodySize(10 << 20) // 10 MiB
+func (m *model) SetMaxBodySize(size int64) {
+	m.request.SetMaxBodySize(size)
+}
+
+// limitBody limita a leitura de body a limit bytes. Ao ultrapassar o limite,
+// a leitura falha com *BodyTooLargeError. Um limite menor ou igual a zero não limita.
+func limitBody(body io.ReadCloser, limit int64) io.ReadCloser {
+	if limit <= 0 {
+		return body
+	}
+	return &limitedBody{ReadCloser: body, limit: limit}
+}
+
+// limitedBody é um corpo de resposta com tamanho máximo.
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+// Read lê do corpo até o limite configurado.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, &BodyTooLargeError{Limit: b.limit}
+	}
+	// Read one byte past the limit to detect larger bodies
+	if max := b.limit - b.read + 1; int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), &BodyTooLargeError{Limit: b.limit}
+	}
+	return n, err
+}
+
+// readBody lê o corpo inteiro da resposta de uma chamada, respeitando o tamanho
+// máximo e reportando o progresso do recebimento. Preenche WithRawBody, quando usado.
+func (m *model) readBody(resp *http.Response, options *callOptions) ([]byte, *httpError) {
+	limit := options.bodyLimit(m.request.maxBodySize)
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, m.wrapError(http.StatusInternalServerError, &BodyTooLargeError{Limit: limit}, "Houve um erro interno no servidor! C: 04")
+	}
+
+	download := newProgressTracker(options.download)
+	defer download.finish()
+
+	data, err := io.ReadAll(limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), limit))
+	if err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 04")
+	}
+	if options.raw != nil {
+		*options.raw = data
+	}
+	return data, nil
+}
+
+// decodeBody decodifica body em dest usando o codec do Content-Type informado,
+// sem carregar o corpo inteiro em memória. Quando nenhum codec é encontrado,
+// ou quando a resposta é text/plain e dest não é *string, o corpo é tratado como JSON.
+// O restante do corpo é descartado ao final, para que a conexão possa ser reutilizada.
+func decodeBody(contentType string, body io.Reader, dest interface{}, options *callOptions) error {
+	var raw *bytes.Buffer
+	if options.raw != nil {
+		raw = &bytes.Buffer{}
+		body = io.TeeReader(body, raw)
+		defer func() { *options.raw = raw.Bytes() }()
+	}
+
+	err := decodeInto(contentType, body, dest, options)
+	if err == nil || err == io.EOF {
+		_, err = io.Copy(io.Discard, body)
+	}
+	return err
+}
+
+// decodeInto decodifica body em dest.
+func decodeInto(contentType string, body io.Reader, dest interface{}, options *callOptions) error {
+	if dest == nil {
+		return nil
+	}
+	if b, ok := dest.(*[]byte); ok {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		if len(data) > 0 {
+			*b = data
+		}
+		return nil
+	}
+
+	codec := codecs.lookup(contentType)
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+
+	// Many servers send JSON as text/plain
+	if _, ok := codec.(textCodec); ok {
+		if _, ok := dest.(*string); !ok {
+			codec = jsonCodec{}
+		}
+	}
+
+	if _, ok := codec.(jsonCodec); ok {
+		decoder := json.NewDecoder(body)
+		if options.disallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		if options.useNumber {
+			decoder.UseNumber()
+		}
+		return decoder.Decode(dest)
+	}
+	if c, ok := codec.(csvCodec); ok {
+		return c.withOptions(options).Decode(body, dest)
+	}
+	return codec.Decode(body, dest)
+}
+
+// readErrRecorder registra o primeiro erro de leitura do corpo (ex: conexão
+// interrompida, limite de tamanho), separando-o dos erros de decodificação.
+type readErrRecorder struct {
+	r   io.Reader
+	err error
+}
+
+// Read lê de r e registra o primeiro erro diferente de io.EOF.
+func (e *readErrRecorder) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && err != io.EOF && e.err == nil {
+		e.err = err
+	}
+	return n, err
+}
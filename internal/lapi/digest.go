@@ -0,0 +1,241 @@
+package lapi
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestAuth é uma implementação de ChallengeAuthenticator para HTTP Digest (RFC 7616).
+// Suporta os algoritmos MD5 e SHA-256 (e suas variantes -sess) com qop=auth.
+// O nonce recebido no desafio é reutilizado nas chamadas seguintes, com o
+// contador nc incrementado a cada requisição.
+type digestAuth struct {
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        uint32
+}
+
+// digestChallenge representa os parâmetros do header WWW-Authenticate: Digest.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+}
+
+// NewDigestAuth cria um Authenticator HTTP Digest.
+//
+// Parâmetros:
+//   - username: Nome de usuário
+//   - password: Senha
+//
+// Exemplo:
+//
+//	r.SetAuthenticator(NewDigestAuth("usuario", "senha"))
+func NewDigestAuth(username, password string) *digestAuth {
+	return &digestAuth{
+		username: username,
+		password: password,
+	}
+}
+
+// Authenticate adiciona o header Authorization: Digest à requisição.
+// Enquanto nenhum desafio tiver sido recebido, a requisição é enviada sem credenciais.
+func (a *digestAuth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.challenge == nil {
+		return nil
+	}
+
+	a.nc++
+	header, err := a.authorization(req.Method, req.URL.RequestURI(), a.nc)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", header)
+	return nil
+}
+
+// Challenge processa o desafio Digest da resposta 401.
+// Retorna false quando a resposta não contém um desafio Digest suportado.
+func (a *digestAuth) Challenge(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+
+	var selected *digestChallenge
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		c := parseDigestChallenge(header)
+		if c == nil || digestHash(c.algorithm) == nil {
+			continue
+		}
+		// Prefer SHA-256 over MD5 when the server offers both
+		if selected == nil || strings.HasPrefix(strings.ToUpper(c.algorithm), "SHA-256") {
+			selected = c
+		}
+	}
+	if selected == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.challenge = selected
+	a.nc = 0
+	return true
+}
+
+// authorization monta o valor do header Authorization para o método e URI informados.
+func (a *digestAuth) authorization(method, uri string, nc uint32) (string, error) {
+	c := a.challenge
+	newHash := digestHash(c.algorithm)
+
+	cnonce, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	count := fmt.Sprintf("%08x", nc)
+
+	ha1 := digestSum(newHash, a.username+":"+c.realm+":"+a.password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), "-sess") {
+		ha1 = digestSum(newHash, ha1+":"+c.nonce+":"+cnonce)
+	}
+	ha2 := digestSum(newHash, method+":"+uri)
+
+	var response string
+	if c.qop == "" {
+		response = digestSum(newHash, ha1+":"+c.nonce+":"+ha2)
+	} else {
+		response = digestSum(newHash, strings.Join([]string{ha1, c.nonce, count, cnonce, c.qop, ha2}, ":"))
+	}
+
+	username := a.username
+	if c.userhash {
+		username = digestSum(newHash, a.username+":"+c.realm)
+	}
+
+	params := []string{
+		fmt.Sprintf("username=%q", username),
+		fmt.Sprintf("realm=%q", c.realm),
+		fmt.Sprintf("nonce=%q", c.nonce),
+		fmt.Sprintf("uri=%q", uri),
+		fmt.Sprintf("response=%q", response),
+	}
+	if c.algorithm != "" {
+		params = append(params, "algorithm="+c.algorithm)
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+count, fmt.Sprintf("cnonce=%q", cnonce))
+	}
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf("opaque=%q", c.opaque))
+	}
+	if c.userhash {
+		params = append(params, "userhash=true")
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+// parseDigestChallenge interpreta um header WWW-Authenticate do esquema Digest.
+// Retorna nil se o header não for um desafio Digest ou se não exigir qop=auth.
+func parseDigestChallenge(header string) *digestChallenge {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil
+	}
+
+	params := parseAuthParams(rest)
+	c := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		userhash:  strings.EqualFold(params["userhash"], "true"),
+	}
+	if c.nonce == "" {
+		return nil
+	}
+
+	if qop, ok := params["qop"]; ok {
+		for _, option := range strings.Split(qop, ",") {
+			if strings.TrimSpace(option) == "auth" {
+				c.qop = "auth"
+			}
+		}
+		// Only qop=auth is supported
+		if c.qop == "" {
+			return nil
+		}
+	}
+	return c
+}
+
+// parseAuthParams interpreta a lista de parâmetros chave=valor de um desafio HTTP,
+// respeitando valores entre aspas que contenham vírgulas.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value = b.String()
+			if i < len(rest) {
+				i++
+			}
+			s = rest[i:]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// digestHash retorna a função de hash do algoritmo Digest informado.
+// Retorna nil se o algoritmo não for suportado.
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5", "MD5-SESS":
+		return md5.New
+	case "SHA-256", "SHA-256-SESS":
+		return sha256.New
+	}
+	return nil
+}
+
+// digestSum calcula o hash hexadecimal de s.
+func digestSum(newHash func() hash.Hash, s string) string {
+	h := newHash()
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
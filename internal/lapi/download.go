@@ -0,0 +1,338 @@
+package lapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChecksumMismatch indica que o conteúdo baixado não corresponde ao checksum esperado.
+var ErrChecksumMismatch = errors.New("lapi: checksum do download não confere")
+
+// DownloadInfo contém as informações de um download concluído.
+type DownloadInfo struct {
+	// Filename é o nome do arquivo informado em Content-Disposition ou,
+	// na falta dele, o último segmento do caminho da URL.
+	Filename string
+
+	// Path é o caminho final do arquivo (apenas em DownloadFile).
+	Path string
+
+	// Size é a quantidade de bytes baixados.
+	Size int64
+
+	// ContentType é o Content-Type da resposta.
+	ContentType string
+
+	// SHA256 é o hash SHA-256 do conteúdo baixado, em hexadecimal.
+	SHA256 string
+}
+
+// WithChecksum verifica o SHA-256 do conteúdo baixado por Download e DownloadFile.
+// Se o hash não conferir, a chamada falha com um erro que corresponde a ErrChecksumMismatch.
+//
+// Exemplo:
+//
+//	info, err := m.DownloadFile("/files/1", "/tmp/", WithChecksum("9f86d081884c7d65..."))
+func WithChecksum(sha256Hex string) CallOption {
+	return func(o *callOptions) {
+		o.checksum = strings.ToLower(sha256Hex)
+	}
+}
+
+// WithContentDigest verifica o conteúdo baixado por Download e DownloadFile com o
+// header Content-Digest (ou Repr-Digest) da resposta, com sha-256 ou sha-512 (RFC 9530).
+// A chamada falha se o header não existir ou se o hash não conferir.
+//
+// Exemplo:
+//
+//	info, err := m.Download("/exports/1", w, WithContentDigest())
+func WithContentDigest() CallOption {
+	return func(o *callOptions) {
+		o.contentDigest = true
+	}
+}
+
+// Download baixa o conteúdo de path e o escreve em w sem carregá-lo em memória,
+// independentemente do Content-Type. Como w pode já ter recebido parte do conteúdo
+// quando a chamada falha, use DownloadFile para gravar arquivos.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/files/1")
+//   - w: Destino do conteúdo
+//   - opts: Opções da chamada (ex: WithChecksum, WithContentDigest, WithMaxBodySize)
+//
+// Exemplo:
+//
+//	var buf bytes.Buffer
+//	info, err := m.Download("/reports/1.pdf", &buf)
+//
+// Retorna as informações do download ou um erro HTTP se a chamada falhar.
+func (m *model) Download(path string, w io.Writer, opts ...CallOption) (*DownloadInfo, *httpError) {
+	options := newCallOptions(opts)
+	resp, httpErr := m.startDownload(path, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	defer resp.Body.Close()
+
+	info := newDownloadInfo(resp)
+	if httpErr := m.copyDownload(w, resp, options, info); httpErr != nil {
+		return nil, httpErr
+	}
+	return info, nil
+}
+
+// DownloadFile baixa o conteúdo de path para um arquivo.
+// O conteúdo é gravado em um arquivo temporário no mesmo diretório e renomeado
+// apenas ao final, de forma que nenhum arquivo parcial permaneça em caso de falha.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/files/1")
+//   - target: Caminho do arquivo ou de um diretório existente (ou terminado em "/");
+//     no caso de um diretório, o nome do arquivo vem de Content-Disposition ou da URL
+//   - opts: Opções da chamada (ex: WithChecksum, WithContentDigest, WithMaxBodySize, WithResume)
+//
+// Exemplo:
+//
+//	info, err := m.DownloadFile("/reports/1", "/tmp/relatorios/")
+//	fmt.Println(info.Path) // /tmp/relatorios/relatorio-2024.pdf
+//
+// Retorna as informações do download ou um erro HTTP se a chamada falhar.
+func (m *model) DownloadFile(path, target string, opts ...CallOption) (*DownloadInfo, *httpError) {
+	options := newCallOptions(opts)
+	if options.resume {
+		return m.resumeDownload(path, target, options)
+	}
+	resp, httpErr := m.startDownload(path, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	defer resp.Body.Close()
+
+	info := newDownloadInfo(resp)
+	info.Path = downloadPath(target, info.Filename)
+
+	tmp, err := os.CreateTemp(filepath.Dir(info.Path), "."+filepath.Base(info.Path)+".part-*")
+	if err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	defer os.Remove(tmp.Name())
+
+	if httpErr := m.copyDownload(tmp, resp, options, info); httpErr != nil {
+		tmp.Close()
+		return nil, httpErr
+	}
+	if err := finishFile(tmp, info.Path); err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	return info, nil
+}
+
+// startDownload envia a requisição GET do download e valida o status da resposta.
+// O conteúdo é pedido sem Content-Encoding, para que os bytes e o Content-Digest
+// correspondam ao arquivo original.
+func (m *model) startDownload(path string, options *callOptions) (*http.Response, *httpError) {
+	if headerValue(options.headers, "Accept-Encoding") == "" {
+		WithHeader("Accept-Encoding", "identity")(options)
+	}
+
+	resp, httpErr := m.exchange(http.MethodGet, path, nil, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+	return resp, nil
+}
+
+// copyDownload copia o corpo da resposta para w, respeitando o tamanho máximo
+// e verificando os checksums configurados.
+func (m *model) copyDownload(w io.Writer, resp *http.Response, options *callOptions, info *DownloadInfo) *httpError {
+	limit := options.bodyLimit(m.request.maxBodySize)
+	if limit > 0 && resp.ContentLength > limit {
+		return m.wrapError(http.StatusInternalServerError, &BodyTooLargeError{Limit: limit}, "Houve um erro interno no servidor! C: 04")
+	}
+
+	hasher, err := newDownloadHasher(options, resp.Header, "Content-Digest", "Repr-Digest")
+	if err != nil {
+		return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 10")
+	}
+
+	download := newProgressTracker(options.download)
+	defer download.finish()
+
+	body := &readErrRecorder{r: limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), limit)}
+	n, err := io.Copy(io.MultiWriter(w, hasher), body)
+	if err != nil {
+		if body.err != nil {
+			return m.wrapError(http.StatusInternalServerError, body.err, "Houve um erro interno no servidor! C: 04")
+		}
+		return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	info.Size = n
+	info.SHA256 = hasher.sha256Hex()
+
+	if err := hasher.verify(); err != nil {
+		return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 10")
+	}
+	return nil
+}
+
+// downloadHasher calcula o SHA-256 do conteúdo baixado e verifica os checksums configurados.
+type downloadHasher struct {
+	sum      hash.Hash
+	digest   hash.Hash
+	expected []byte
+	checksum string
+}
+
+// newDownloadHasher cria o hasher do download. Quando WithContentDigest é usado,
+// o hash esperado é lido do primeiro header de names que o contiver.
+func newDownloadHasher(options *callOptions, header http.Header, names ...string) (*downloadHasher, error) {
+	h := &downloadHasher{sum: sha256.New(), checksum: options.checksum}
+	if !options.contentDigest {
+		return h, nil
+	}
+
+	algorithm, expected, err := parseContentDigest(header, names...)
+	if err != nil {
+		return nil, err
+	}
+	h.digest, h.expected = h.sum, expected
+	if algorithm == "sha-512" {
+		h.digest = sha512.New()
+	}
+	return h, nil
+}
+
+// Write adiciona p aos hashes.
+func (h *downloadHasher) Write(p []byte) (int, error) {
+	h.sum.Write(p)
+	if h.digest != nil && h.digest != h.sum {
+		h.digest.Write(p)
+	}
+	return len(p), nil
+}
+
+// sha256Hex retorna o SHA-256 do conteúdo, em hexadecimal.
+func (h *downloadHasher) sha256Hex() string {
+	return hex.EncodeToString(h.sum.Sum(nil))
+}
+
+// verify compara os hashes do conteúdo com o checksum e o Content-Digest esperados.
+func (h *downloadHasher) verify() error {
+	if sum := h.sha256Hex(); h.checksum != "" && h.checksum != sum {
+		return fmt.Errorf("%w: esperado sha256 %s, recebido %s", ErrChecksumMismatch, h.checksum, sum)
+	}
+	if h.digest != nil && !bytes.Equal(h.digest.Sum(nil), h.expected) {
+		return fmt.Errorf("%w: Content-Digest diferente do conteúdo recebido", ErrChecksumMismatch)
+	}
+	return nil
+}
+
+// newDownloadInfo cria as informações do download a partir dos headers da resposta.
+func newDownloadInfo(resp *http.Response) *DownloadInfo {
+	return &DownloadInfo{
+		Filename:    downloadFilename(resp),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+}
+
+// downloadFilename retorna o nome do arquivo de Content-Disposition ou, na falta
+// dele, o último segmento do caminho da URL. Diretórios do nome são descartados.
+func downloadFilename(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := safeFilename(params["filename"]); name != "" {
+			return name
+		}
+	}
+	if resp.Request != nil {
+		if name := safeFilename(path.Base(resp.Request.URL.Path)); name != "" {
+			return name
+		}
+	}
+	return "download"
+}
+
+// safeFilename remove os diretórios de name e rejeita nomes especiais.
+func safeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	switch name {
+	case ".", "..", "/":
+		return ""
+	}
+	return name
+}
+
+// downloadPath retorna o caminho final do arquivo. Quando target é um diretório
+// (existente ou terminado em separador), filename é usado como nome do arquivo.
+func downloadPath(target, filename string) string {
+	if strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(filepath.Separator)) {
+		return filepath.Join(target, filename)
+	}
+	if stat, err := os.Stat(target); err == nil && stat.IsDir() {
+		return filepath.Join(target, filename)
+	}
+	return target
+}
+
+// finishFile grava o arquivo temporário em disco e o move para path.
+func finishFile(tmp *os.File, path string) error {
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseContentDigest retorna o algoritmo e o hash dos headers de digest informados
+// (ex: Content-Digest, Repr-Digest), preferindo sha-256.
+// Ex: sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:
+func parseContentDigest(header http.Header, names ...string) (string, []byte, error) {
+	digests := map[string]string{}
+	for _, name := range names {
+		for _, item := range strings.Split(header.Get(name), ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			if _, exists := digests[key]; !exists {
+				digests[key] = strings.Trim(strings.TrimSpace(value), ":")
+			}
+		}
+	}
+
+	for _, algorithm := range []string{"sha-256", "sha-512"} {
+		if value, ok := digests[algorithm]; ok {
+			sum, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("lapi: Content-Digest inválido: %w", err)
+			}
+			return algorithm, sum, nil
+		}
+	}
+	return "", nil, errors.New("lapi: a resposta não contém Content-Digest com sha-256 ou sha-512")
+}
@@ -0,0 +1,257 @@
+package lapi
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ContentDecoder é uma interface que representa a descompressão de um
+// Content-Encoding das respostas.
+//
+// Exemplo de uso:
+//
+//	RegisterContentDecoder(brotliDecoder{})
+type ContentDecoder interface {
+	// Encoding retorna o nome do Content-Encoding tratado (ex: "gzip").
+	Encoding() string
+
+	// NewReader retorna um leitor com o conteúdo de r descomprimido.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// ErrDecompressionRatio indica que a resposta descomprimida ultrapassou a razão
+// máxima em relação ao tamanho comprimido (ex: zip bomb).
+var ErrDecompressionRatio = errors.New("lapi: razão máxima de descompressão excedida")
+
+// defaultDecompressionRatio é a razão máxima padrão entre o tamanho descomprimido e o comprimido.
+const defaultDecompressionRatio = 100
+
+// decompressionSlack é a quantidade de bytes descomprimidos sempre aceita,
+// para que respostas pequenas e muito repetitivas não sejam rejeitadas.
+const decompressionSlack = 64 << 10
+
+// decoderRegistry é o registro de decodificadores por Content-Encoding.
+// A ordem de registro define a ordem no header Accept-Encoding.
+type decoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]ContentDecoder
+	order    []string
+}
+
+// contentDecoders é o registro global de decodificadores, com gzip e deflate.
+var contentDecoders = newDecoderRegistry(gzipDecoder{}, deflateDecoder{})
+
+// newDecoderRegistry cria um registro com os decodificadores informados.
+func newDecoderRegistry(list ...ContentDecoder) *decoderRegistry {
+	r := &decoderRegistry{decoders: make(map[string]ContentDecoder)}
+	for _, d := range list {
+		r.register(d)
+	}
+	return r
+}
+
+// RegisterContentDecoder registra um decodificador para o seu Content-Encoding.
+// Se já existir um decodificador para o mesmo encoding, ele é substituído.
+//
+// Parâmetros:
+//   - d: Implementação de ContentDecoder
+//
+// Exemplo:
+//
+//	RegisterContentDecoder(zstdDecoder{})
+func RegisterContentDecoder(d ContentDecoder) {
+	contentDecoders.register(d)
+}
+
+// register adiciona ou substitui um decodificador no registro.
+func (r *decoderRegistry) register(d ContentDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	encoding := strings.ToLower(d.Encoding())
+	if _, ok := r.decoders[encoding]; !ok {
+		r.order = append(r.order, encoding)
+	}
+	r.decoders[encoding] = d
+}
+
+// lookup retorna o decodificador do encoding informado ou nil.
+func (r *decoderRegistry) lookup(encoding string) ContentDecoder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.decoders[strings.ToLower(encoding)]
+}
+
+// acceptEncoding monta o valor do header Accept-Encoding a partir dos decodificadores registrados.
+func (r *decoderRegistry) acceptEncoding() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return strings.Join(r.order, ", ")
+}
+
+// SetMaxDecompressionRatio define a razão máxima entre o tamanho descomprimido
+// e o comprimido das respostas. Por padrão, 100. Um valor negativo desativa o limite.
+//
+// Parâmetros:
+//   - ratio: Razão máxima (ex: 100 permite 1 MB comprimido virar 100 MB)
+//
+// Exemplo:
+//
+//	r.SetMaxDecompressionRatio(50)
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetMaxDecompressionRatio(ratio int64) *request {
+	r.maxRatio = ratio
+	return r
+}
+
+// SetMaxDecompressionRatio define a razão máxima entre o tamanho descomprimido
+// e o comprimido das respostas do modelo. Por padrão, 100. Um valor negativo desativa o limite.
+//
+// Parâmetros:
+//   - ratio: Razão máxima (ex: 100 permite 1 MB comprimido virar 100 MB)
+//
+// Exemplo:
+//
+//	m.SetMaxDecompressionRatio(50)
+func (m *model) SetMaxDecompressionRatio(ratio int64) {
+	m.request.SetMaxDecompressionRatio(ratio)
+}
+
+// decodeResponse substitui o corpo de resp pelo conteúdo descomprimido,
+// desfazendo os encodings na ordem inversa em que foram aplicados
+// (ex: "Content-Encoding: deflate, gzip"). Os headers Content-Encoding e
+// Content-Length são removidos. Retorna um erro se algum encoding não for suportado.
+func decodeResponse(resp *http.Response, maxRatio int64) error {
+	header := resp.Header.Get("Content-Encoding")
+	if header == "" || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+
+	var decoders []ContentDecoder
+	for _, encoding := range strings.Split(header, ",") {
+		encoding = strings.TrimSpace(encoding)
+		if encoding == "" || strings.EqualFold(encoding, "identity") {
+			continue
+		}
+		decoder := contentDecoders.lookup(encoding)
+		if decoder == nil {
+			return fmt.Errorf("lapi: Content-Encoding %q não suportado", encoding)
+		}
+		decoders = append(decoders, decoder)
+	}
+	if maxRatio == 0 {
+		maxRatio = defaultDecompressionRatio
+	}
+
+	resp.Body = &decodingBody{raw: resp.Body, decoders: decoders, maxRatio: maxRatio}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodingBody descomprime o corpo da resposta sob demanda, controlando a
+// razão entre os bytes produzidos e os bytes lidos da conexão.
+// Os decodificadores só são criados na primeira leitura.
+type decodingBody struct {
+	raw      io.ReadCloser
+	decoders []ContentDecoder
+	maxRatio int64
+
+	in      countingReader
+	reader  io.Reader
+	closers []io.Closer
+	out     int64
+	err     error
+}
+
+// Read lê o conteúdo descomprimido.
+func (b *decodingBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.reader == nil {
+		b.in.r = b.raw
+		var r io.Reader = &b.in
+		for i := len(b.decoders) - 1; i >= 0; i-- {
+			rc, err := b.decoders[i].NewReader(r)
+			if err != nil {
+				b.err = err
+				return 0, err
+			}
+			b.closers = append(b.closers, rc)
+			r = rc
+		}
+		b.reader = r
+	}
+
+	n, err := b.reader.Read(p)
+	b.out += int64(n)
+	if b.maxRatio > 0 && b.out > decompressionSlack && b.out > b.maxRatio*b.in.n {
+		b.err = ErrDecompressionRatio
+		return n, b.err
+	}
+	return n, err
+}
+
+// Close fecha os decodificadores e o corpo original.
+func (b *decodingBody) Close() error {
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		b.closers[i].Close()
+	}
+	return b.raw.Close()
+}
+
+// countingReader conta os bytes lidos de r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read lê de r e acumula a quantidade de bytes lidos.
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// gzipDecoder é o decodificador de gzip.
+type gzipDecoder struct{}
+
+// Encoding retorna "gzip".
+func (gzipDecoder) Encoding() string { return "gzip" }
+
+// NewReader retorna um gzip.Reader sobre r.
+func (gzipDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateDecoder é o decodificador de deflate. Aceita o formato zlib (RFC 1950),
+// previsto pelo HTTP, e o deflate sem cabeçalho enviado por alguns servidores.
+type deflateDecoder struct{}
+
+// Encoding retorna "deflate".
+func (deflateDecoder) Encoding() string { return "deflate" }
+
+// NewReader retorna um leitor zlib ou flate sobre r, conforme o cabeçalho do conteúdo.
+func (deflateDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
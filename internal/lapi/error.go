@@ -1,5 +1,7 @@
 package lapi
 
+import "net/http"
+
 // HttpError é uma interface que representa um erro HTTP.
 // Ela fornece métodos para acessar o código de status, a requisição e a resposta associadas ao erro.
 //
@@ -44,6 +46,9 @@ type httpError struct {
 	// response é a resposta associada ao erro.
 	// Pode conter detalhes adicionais sobre o erro retornado pela API.
 	response interface{}
+
+	// cause é o erro técnico que originou o erro HTTP, quando houver.
+	cause error
 }
 
 // Message retorna a mensagem de erro associada ao erro.
@@ -112,3 +117,33 @@ func (m *model) MakeError(statusCode int, err, message string) *httpError {
 func (e *httpError) Error() string {
 	return e.message
 }
+
+// Unwrap retorna o erro técnico que originou o erro HTTP, quando houver.
+// Permite o uso de errors.Is e errors.As.
+func (e *httpError) Unwrap() error {
+	return e.cause
+}
+
+// wrapError cria um novo erro HTTP que mantém o erro técnico de origem.
+//
+// Parâmetros:
+//   - statusCode: Código de status HTTP (ex: 404, 500)
+//   - err: Erro técnico de origem (acessível por errors.Is e errors.As)
+//   - message: Mensagem de erro amigável para o usuário
+func (m *model) wrapError(statusCode int, err error, message string) *httpError {
+	return &httpError{
+		statusCode: statusCode,
+		message:    message,
+		cause:      err,
+	}
+}
+
+// bodyErrorStatus retorna o status de um erro reportado no corpo da resposta
+// (ex: "errors" do GraphQL, "error" do JSON-RPC): o status HTTP quando >= 400 e, senão, 502 Bad Gateway,
+// para que StatusCode nunca indique sucesso.
+func bodyErrorStatus(status int) int {
+	if status < 400 {
+		return http.StatusBadGateway
+	}
+	return status
+}
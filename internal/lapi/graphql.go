@@ -0,0 +1,299 @@
+package lapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// GraphQLLocation é a posição, no documento da query, associada a um erro GraphQL.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError é um item do array "errors" de uma resposta GraphQL.
+type GraphQLError struct {
+	// Message é a mensagem do erro.
+	Message string `json:"message"`
+
+	// Path é o caminho do campo que falhou (nomes de campos e índices).
+	Path []interface{} `json:"path,omitempty"`
+
+	// Locations são as posições do erro no documento da query.
+	Locations []GraphQLLocation `json:"locations,omitempty"`
+
+	// Extensions são os detalhes adicionais informados pelo servidor (ex: "code").
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error implementa a interface error do Go.
+func (e GraphQLError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	path := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		path[i] = fmt.Sprint(p)
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(path, "."))
+}
+
+// Code retorna extensions.code, ou vazio quando o servidor não informa.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors é o array "errors" de uma resposta GraphQL. É a causa do
+// HttpError retornado pelo cliente e pode ser obtido com errors.As.
+//
+// Exemplo de uso:
+//
+//	var gqlErrs GraphQLErrors
+//	if err := gql.Query(q, vars, &dest); err != nil && errors.As(err, &gqlErrs) {
+//	    for _, e := range gqlErrs {
+//	        fmt.Println(e.Code(), e.Message, e.Path)
+//	    }
+//	}
+type GraphQLErrors []GraphQLError
+
+// Error implementa a interface error do Go.
+func (e GraphQLErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "graphql: erro desconhecido"
+	case 1:
+		return "graphql: " + e[0].Error()
+	}
+	return fmt.Sprintf("graphql: %s (e mais %d erros)", e[0].Error(), len(e)-1)
+}
+
+// WithOperationName define o operationName enviado com a query GraphQL,
+// necessário quando o documento contém mais de uma operação.
+//
+// Exemplo:
+//
+//	err := gql.Query(doc, vars, &dest, WithOperationName("GetUser"))
+func WithOperationName(name string) CallOption {
+	return func(o *callOptions) {
+		o.operationName = name
+	}
+}
+
+// graphQLClient executa queries e mutations GraphQL com a configuração do modelo
+// (URL base, headers, autenticação). Os erros do array "errors" são retornados
+// como HttpError mesmo quando o status HTTP é 200; nesse caso, com status 502.
+//
+// Exemplo de uso:
+//
+//	gql := m.GraphQL("/graphql").WithPersistedQueries()
+//
+//	var dest struct {
+//	    User struct {
+//	        ID   string `json:"id"`
+//	        Name string `json:"name"`
+//	    } `json:"user"`
+//	}
+//	err := gql.Query(`query GetUser($id: ID!) { user(id: $id) { id name } }`,
+//	    map[string]interface{}{"id": "42"}, &dest)
+type graphQLClient struct {
+	m    *model
+	path string
+
+	persisted   bool
+	unsupported atomic.Bool
+}
+
+// graphQLRequest é o corpo de uma requisição GraphQL.
+type graphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     interface{}            `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// graphQLResponse é o corpo de uma resposta GraphQL.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQL cria um cliente GraphQL para o endpoint path.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/graphql")
+//
+// Exemplo:
+//
+//	gql := m.GraphQL("/graphql")
+func (m *model) GraphQL(path string) *graphQLClient {
+	return &graphQLClient{m: m, path: path}
+}
+
+// WithPersistedQueries ativa as Automatic Persisted Queries: a requisição envia
+// apenas o hash SHA-256 da query e a query completa só é enviada quando o servidor
+// responde PersistedQueryNotFound. Se o servidor não suportar, o cliente volta a
+// enviar sempre a query completa.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *graphQLClient) WithPersistedQueries() *graphQLClient {
+	c.persisted = true
+	return c
+}
+
+// Query executa uma query GraphQL e decodifica "data" em dest.
+//
+// Parâmetros:
+//   - query: Documento GraphQL
+//   - variables: Variáveis da operação (struct ou map, codificadas em JSON), ou nil
+//   - dest: Ponteiro que receberá o conteúdo de "data"
+//   - opts: Opções da chamada (ex: WithOperationName, WithContext)
+//
+// Quando a resposta contém "data" e "errors" (resultado parcial), dest é preenchido
+// e os erros são retornados.
+//
+// Retorna:
+//   - *httpError: Erro HTTP ou GraphQL (causa GraphQLErrors), nil caso contrário
+func (c *graphQLClient) Query(query string, variables interface{}, dest interface{}, opts ...CallOption) *httpError {
+	return c.execute(query, variables, dest, opts)
+}
+
+// Mutate executa uma mutation GraphQL e decodifica "data" em dest.
+//
+// Parâmetros:
+//   - mutation: Documento GraphQL
+//   - variables: Variáveis da operação (struct ou map, codificadas em JSON), ou nil
+//   - dest: Ponteiro que receberá o conteúdo de "data"
+//   - opts: Opções da chamada (ex: WithOperationName, WithContext)
+//
+// Exemplo:
+//
+//	input := CreateUserInput{Name: "Ana"}
+//	err := gql.Mutate(`mutation($input: CreateUserInput!) { createUser(input: $input) { id } }`,
+//	    map[string]interface{}{"input": input}, &dest)
+//
+// Retorna:
+//   - *httpError: Erro HTTP ou GraphQL (causa GraphQLErrors), nil caso contrário
+func (c *graphQLClient) Mutate(mutation string, variables interface{}, dest interface{}, opts ...CallOption) *httpError {
+	return c.execute(mutation, variables, dest, opts)
+}
+
+// execute envia a operação, com o fluxo de persisted queries quando ativo.
+func (c *graphQLClient) execute(query string, variables interface{}, dest interface{}, opts []CallOption) *httpError {
+	options := newCallOptions(opts)
+	body := graphQLRequest{Query: query, OperationName: options.operationName, Variables: variables}
+
+	if c.persisted && !c.unsupported.Load() {
+		sum := sha256.Sum256([]byte(query))
+		body.Extensions = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{
+				"version":    1,
+				"sha256Hash": hex.EncodeToString(sum[:]),
+			},
+		}
+		body.Query = ""
+
+		resp, httpErr := c.send(body, opts)
+		if httpErr != nil {
+			return httpErr
+		}
+		switch persistedQueryError(resp.Errors) {
+		case "":
+			return c.finish(resp, dest, options)
+		case "PersistedQueryNotSupported":
+			c.unsupported.Store(true)
+			body.Extensions = nil
+		}
+		// Register the query on the server with the hash
+		body.Query = query
+	}
+
+	resp, httpErr := c.send(body, opts)
+	if httpErr != nil {
+		return httpErr
+	}
+	return c.finish(resp, dest, options)
+}
+
+// graphQLResult é uma resposta GraphQL lida, com o status HTTP.
+type graphQLResult struct {
+	graphQLResponse
+	status int
+	reason string
+}
+
+// send envia o corpo e lê a resposta GraphQL.
+func (c *graphQLClient) send(body graphQLRequest, opts []CallOption) (*graphQLResult, *httpError) {
+	m := c.m
+	options := newCallOptions(append([]CallOption{
+		WithContentType("application/json"),
+		WithHeader("Accept", "application/graphql-response+json, application/json"),
+	}, opts...))
+
+	var payload interface{} = body
+	resp, httpErr := m.exchange(http.MethodPost, c.path, &payload, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	defer resp.Body.Close()
+
+	data, httpErr := m.readBody(resp, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	result := &graphQLResult{status: resp.StatusCode, reason: resp.Status}
+	if err := json.Unmarshal(data, &result.graphQLResponse); err != nil || (result.Data == nil && len(result.Errors) == 0) {
+		if resp.StatusCode >= 400 {
+			return nil, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+		}
+		if err == nil {
+			err = errors.New("graphql: resposta sem data e sem errors")
+		}
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+	}
+	return result, nil
+}
+
+// finish decodifica "data" em dest e converte "errors" em HttpError.
+func (c *graphQLClient) finish(result *graphQLResult, dest interface{}, options *callOptions) *httpError {
+	m := c.m
+	if dest != nil && len(result.Data) > 0 && !bytes.Equal(result.Data, []byte("null")) {
+		if err := decodeInto("application/json", bytes.NewReader(result.Data), dest, options); err != nil {
+			if options.disallowUnknownFields {
+				return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 08")
+			}
+			log.Println(err.Error())
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return m.wrapError(bodyErrorStatus(result.status), result.Errors, result.Errors.Error())
+	}
+	if result.status >= 400 {
+		return m.MakeError(result.status, result.reason, "Status code >= 400")
+	}
+	return nil
+}
+
+// persistedQueryError retorna "PersistedQueryNotFound" ou "PersistedQueryNotSupported"
+// quando um dos erros indica a falha de uma persisted query, ou vazio.
+func persistedQueryError(errs GraphQLErrors) string {
+	for _, e := range errs {
+		switch {
+		case e.Message == "PersistedQueryNotFound", e.Code() == "PERSISTED_QUERY_NOT_FOUND":
+			return "PersistedQueryNotFound"
+		case e.Message == "PersistedQueryNotSupported", e.Code() == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return "PersistedQueryNotSupported"
+		}
+	}
+	return ""
+}
@@ -1,6 +1,11 @@
 package lapi
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // SetHeaders define múltiplos cabeçalhos HTTP para a requisição de uma vez.
 // Esta função substitui todos os cabeçalhos existentes.
@@ -17,8 +22,22 @@ import "encoding/json"
 //	})
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
+// Headers inválidos não são definidos; o erro é registrado e retornado por Err.
 func (r *request) SetHeaders(headers map[string]string) *request {
-	r.headers = headers
+	for key := range r.errs {
+		if strings.HasPrefix(key, "header:") {
+			r.setErr(key, nil)
+		}
+	}
+
+	r.headers = make(map[string]string, len(headers))
+	for key, value := range headers {
+		if err := validateHeader(key, value); err != nil {
+			r.setErr(headerErrKey(key), err)
+			continue
+		}
+		r.headers[key] = value
+	}
 	return r
 }
 
@@ -34,7 +53,16 @@ func (r *request) SetHeaders(headers map[string]string) *request {
 //	r.SetHeader("Content-Type", "application/json")
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
+// Se o header for inválido, o erro é registrado e retornado por Err.
 func (r *request) SetHeader(key, value string) *request {
+	if err := validateHeader(key, value); err != nil {
+		r.setErr(headerErrKey(key), err)
+		return r
+	}
+	r.setErr(headerErrKey(key), nil)
+	if r.headers == nil {
+		r.headers = make(map[string]string)
+	}
 	r.headers[key] = value
 	return r
 }
@@ -53,12 +81,19 @@ func (r *request) SetHeader(key, value string) *request {
 //	})
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
-// Se houver erro na conversão para JSON, o cabeçalho não será definido.
+// Se houver erro na conversão para JSON, o cabeçalho não será definido
+// e o erro será registrado e retornado por Err.
 func (r *request) SetHeaderJSON(key string, value interface{}) *request {
 	jsonBody, err := json.Marshal(value)
 	if err != nil {
+		r.setErr(headerErrKey(key), fmt.Errorf("lapi: não foi possível converter o header %q para JSON: %w", key, err))
 		return r
 	}
-	r.headers[key] = string(jsonBody)
-	return r
+	return r.SetHeader(key, string(jsonBody))
+}
+
+// headerErrKey retorna a chave do erro de um header. Usa a forma canônica do nome
+// para que "content-type" e "Content-Type" compartilhem o mesmo erro.
+func headerErrKey(key string) string {
+	return "header:" + http.CanonicalHeaderKey(key)
 }
@@ -1,10 +1,17 @@
 package lapi
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
+// errBodyNotReplayable indica que o corpo da requisição já foi consumido e não pode ser reenviado.
+var errBodyNotReplayable = errors.New("lapi: o corpo da requisição não pode ser reenviado")
+
 // OutOfContext retorna uma nova requisição HTTP fora do contexto.
 // Esta função é útil quando você precisa fazer uma requisição HTTP
 // sem usar o contexto padrão do modelo.
@@ -44,21 +51,190 @@ func OutOfContext() *request {
 //
 // Retorna:
 //   - *http.Response: Resposta HTTP
-//   - error: Erro, se ocorrer algum problema durante a requisição
+//   - error: Erro, se ocorrer algum problema durante a requisição ou se
+//     a requisição tiver erros de construção (ver Err)
 func (r *request) Send() (*http.Response, error) {
-	req, err := http.NewRequest(r.method, r.baseURL, r.body)
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	if r.baseURL == "" {
+		return nil, errors.New("lapi: URL base não definida")
+	}
+
+	req, err := newHTTPRequest(r.method, r.baseURL, r.body, r.source)
+	if err != nil {
+		return nil, err
+	}
+	setHeaders(req, r.headers, r.contentType)
+	if r.source != nil && r.source.contentType != "" {
+		req.Header.Set("Content-Type", r.source.contentType)
+	}
+	if err := compressBody(req, r.compressor, r.compressThreshold); err != nil {
+		return nil, err
+	}
+
+	upload := newProgressTracker(r.upload)
+	resp, err := r.roundTrip(withUploadTracker(req, upload))
+	upload.finish()
 	if err != nil {
 		return nil, err
 	}
-	for key, value := range r.headers {
+	if download := newProgressTracker(r.download); download != nil {
+		download.reset(0, contentLength(resp))
+		resp.Body = &progressReader{ReadCloser: resp.Body, tracker: download, finishOnEOF: true}
+	}
+	resp.Body = limitBody(resp.Body, r.maxBodySize)
+
+	return resp, nil
+}
+
+// setHeaders copia os headers para req.
+// O Content-Type só é enviado quando a requisição tem corpo e, se o usuário
+// não o definiu, é usado contentType (o tipo do corpo). Accept, Accept-Encoding
+// e User-Agent recebem valores padrão quando não definidos.
+func setHeaders(req *http.Request, headers map[string]string, contentType string) {
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	for key, value := range headers {
+		if !hasBody && strings.EqualFold(key, "Content-Type") {
+			continue
+		}
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if hasBody && contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", codecs.accept())
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", contentDecoders.acceptEncoding())
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+}
+
+// newHTTPRequest cria a requisição HTTP com o corpo informado.
+// Quando source é definido, o corpo é gerado por ele e, se possível,
+// req.GetBody é configurado para recriá-lo em caso de reenvio.
+func newHTTPRequest(method, url string, body io.Reader, source *bodySource) (*http.Request, error) {
+	if source == nil {
+		return http.NewRequest(method, url, body)
+	}
+
+	rc, err := source.open()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, rc)
 	if err != nil {
+		rc.Close()
 		return nil, err
 	}
+	if source.replayable {
+		req.GetBody = source.open
+	}
+	return req, nil
+}
 
+// roundTrip executa a requisição HTTP aplicando o Authenticator configurado.
+// Quando o Authenticator responde a desafios (ex: Digest, rodízio de API keys),
+// uma resposta de erro é tratada automaticamente e a requisição é reenviada uma única vez.
+func (r *request) roundTrip(req *http.Request) (*http.Response, error) {
+	if err := r.prepare(req); err != nil {
+		return nil, err
+	}
+
+	client := r.client()
+	if req.Context().Value(streamingKey{}) != nil {
+		client.Timeout = 0
+	}
+	resp, err := r.do(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	challenger, ok := r.auth.(ChallengeAuthenticator)
+	if !ok || resp.StatusCode < 400 || !challenger.Challenge(resp) {
+		return resp, nil
+	}
+
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return resp, nil
+	}
+	if err := r.prepare(retry); err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	return r.do(client, retry)
+}
+
+// do envia a requisição e descomprime o corpo da resposta conforme o Content-Encoding.
+func (r *request) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	trackUpload(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeResponse(resp, r.maxRatio); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
 	return resp, nil
 }
+
+// client cria o cliente HTTP com o timeout e o transporte configurados.
+func (r *request) client() *http.Client {
+	return &http.Client{
+		Timeout:   r.timeout,
+		Transport: r.transport,
+	}
+}
+
+// streamingKey marca, no contexto, as requisições de streaming (ex: SSE, NDJSON).
+// Elas não usam o timeout do cliente, pois a resposta pode durar indefinidamente;
+// o fim é controlado pelo contexto da chamada.
+type streamingKey struct{}
+
+// withStreaming marca req como uma requisição de streaming.
+func withStreaming(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), streamingKey{}, true))
+}
+
+// prepare aplica a autenticação e, em seguida, a assinatura à requisição.
+// É chamada a cada envio, de forma que a assinatura reflita o estado final da requisição.
+func (r *request) prepare(req *http.Request) error {
+	if r.auth != nil {
+		if err := r.auth.Authenticate(req); err != nil {
+			return err
+		}
+	}
+	if r.signer != nil {
+		if err := r.signer.Sign(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneRequest cria uma cópia de req pronta para ser reenviada, recriando o corpo.
+// Retorna errBodyNotReplayable se o corpo já foi consumido e não pode ser recriado.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+	if req.GetBody == nil {
+		return nil, errBodyNotReplayable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+	return clone, nil
+}
@@ -0,0 +1,192 @@
+package lapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+)
+
+// JSONArray faz uma requisição GET para path e decodifica, um a um, os elementos
+// de um array JSON da resposta, sem carregar o documento inteiro em memória.
+//
+// O array é escolhido por arrayPath, com as chaves dos objetos separadas por ponto
+// (ex: "data.items"); vazio indica que a resposta já é um array. Um elemento que não
+// corresponde a T (tipo incompatível ou, com WithDisallowUnknownFields, campo desconhecido)
+// é entregue como erro e a iteração continua; erros de status, de sintaxe ou caminho
+// inexistente encerram a iteração. Interromper o laço fecha a conexão.
+// O timeout do cliente não se aplica; use WithContext para limitar a duração.
+//
+// Parâmetros:
+//   - m: Modelo com a configuração do cliente
+//   - path: Caminho do endpoint (ex: "/orders")
+//   - arrayPath: Caminho do array na resposta (ex: "data.items")
+//   - opts: Opções da chamada (ex: WithContext, WithUseNumber, WithDisallowUnknownFields)
+//
+// Exemplo:
+//
+//	// {"data": {"total": 1000000, "items": [{...}, {...}]}}
+//	for order, err := range JSONArray[Order](m, "/orders", "data.items") {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(order)
+//	}
+func JSONArray[T any](m *model, path, arrayPath string, opts ...CallOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		options := newCallOptions(append([]CallOption{WithHeader("Accept", "application/json")}, opts...))
+		options.stream = true
+		resp, httpErr := m.exchange(http.MethodGet, path, nil, options)
+		if httpErr != nil {
+			yield(zero, httpErr)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			yield(zero, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400"))
+			return
+		}
+
+		download := newProgressTracker(options.download)
+		defer download.finish()
+
+		decoder := json.NewDecoder(limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), options.bodyLimit(m.request.maxBodySize)))
+		if options.disallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		if options.useNumber {
+			decoder.UseNumber()
+		}
+
+		found, err := seekJSONArray(decoder, arrayPath)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if !found {
+			return
+		}
+
+		label := arrayPath
+		if label == "" {
+			label = "$"
+		}
+		for index := 0; decoder.More(); index++ {
+			var item T
+			if err := decoder.Decode(&item); err != nil {
+				if !jsonElementError(err) {
+					yield(zero, fmt.Errorf("lapi: elemento %d de %q: %w", index, label, err))
+					return
+				}
+				if !yield(zero, fmt.Errorf("lapi: elemento %d de %q: %w", index, label, err)) {
+					return
+				}
+				continue
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			yield(zero, fmt.Errorf("lapi: fim do array %q: %w", label, err))
+		}
+	}
+}
+
+// jsonElementError indica se err afeta apenas o elemento decodificado, que já foi
+// consumido por inteiro, permitindo continuar com o próximo elemento.
+func jsonElementError(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return true
+	}
+	// DisallowUnknownFields reports unknown fields with an unexported error type
+	return strings.HasPrefix(err.Error(), "json: unknown field ")
+}
+
+// seekJSONArray avança o decoder até o início do array indicado por arrayPath,
+// consumindo o delimitador "[". Retorna false, sem erro, quando o valor é null.
+func seekJSONArray(decoder *json.Decoder, arrayPath string) (bool, error) {
+	var keys []string
+	if arrayPath != "" {
+		keys = strings.Split(arrayPath, ".")
+	}
+
+	for depth, key := range keys {
+		if err := expectDelim(decoder, '{', strings.Join(keys[:depth], ".")); err != nil {
+			return false, err
+		}
+		for {
+			if !decoder.More() {
+				return false, fmt.Errorf("lapi: caminho %q não encontrado na resposta", arrayPath)
+			}
+			token, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+			if token == key {
+				break
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	if token == nil {
+		return false, nil
+	}
+	if token != json.Delim('[') {
+		return false, fmt.Errorf("lapi: %q não é um array JSON", arrayPath)
+	}
+	return true, nil
+}
+
+// expectDelim lê o próximo token e verifica se é o delimitador informado.
+func expectDelim(decoder *json.Decoder, delim json.Delim, path string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		if path == "" {
+			path = "$"
+		}
+		return fmt.Errorf("lapi: %q não é um objeto JSON", path)
+	}
+	return nil
+}
+
+// skipJSONValue descarta o próximo valor do decoder token a token, sem carregá-lo em memória.
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
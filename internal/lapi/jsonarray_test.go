@@ -0,0 +1,44 @@
+package lapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONArrayElementErrors(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"items": [{"id": 1}, {"id": 2, "extra": true}, {"id": "três"}, {"id": 4}]}}`)
+	}))
+	defer srv.Close()
+
+	var ids []int
+	var errs []string
+	for v, err := range JSONArray[item](NewRequest(srv.URL, nil, 5), "/", "data.items", WithDisallowUnknownFields()) {
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		ids = append(ids, v.ID)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 4 {
+		t.Errorf("ids = %v, want [1 4]", ids)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("erros = %q, esperados 2", errs)
+	}
+	if !strings.Contains(errs[0], `elemento 1 de "data.items"`) || !strings.Contains(errs[0], `unknown field "extra"`) {
+		t.Errorf("erro do campo desconhecido = %q", errs[0])
+	}
+	if !strings.Contains(errs[1], `elemento 2 de "data.items"`) {
+		t.Errorf("erro de tipo = %q", errs[1])
+	}
+}
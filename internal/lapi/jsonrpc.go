@@ -0,0 +1,326 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// Códigos de erro definidos pela especificação JSON-RPC 2.0.
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+)
+
+// RPCError é o objeto "error" de uma resposta JSON-RPC 2.0.
+// É a causa do HttpError retornado por Call e pode ser obtido com errors.As.
+// Quando a resposta HTTP é 200, o HttpError tem status 502.
+//
+// Exemplo de uso:
+//
+//	var rpcErr *RPCError
+//	if err := rpc.Call(ctx, "eth_call", params, &result); err != nil && errors.As(err, &rpcErr) {
+//	    fmt.Println(rpcErr.Code, rpcErr.Message, string(rpcErr.Data))
+//	}
+type RPCError struct {
+	// Code é o código do erro (ex: RPCMethodNotFound).
+	Code int `json:"code"`
+
+	// Message é a descrição do erro.
+	Message string `json:"message"`
+
+	// Data são os detalhes adicionais informados pelo servidor, em JSON.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implementa a interface error do Go.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// DecodeData decodifica o campo "data" do erro em dest.
+func (e *RPCError) DecodeData(dest interface{}) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, dest)
+}
+
+// rpcRequest é uma requisição ou notificação JSON-RPC 2.0.
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      *uint64     `json:"id,omitempty"`
+}
+
+// rpcResponse é uma resposta JSON-RPC 2.0.
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// rpcClient é um cliente JSON-RPC 2.0 sobre HTTP, com a configuração do modelo
+// (URL base, headers, autenticação). Os ids são gerados automaticamente.
+//
+// Exemplo de uso:
+//
+//	rpc := m.JSONRPC("/")
+//
+//	var block string
+//	err := rpc.Call(ctx, "eth_blockNumber", nil, &block)
+type rpcClient struct {
+	m    *model
+	path string
+
+	nextID atomic.Uint64
+}
+
+// JSONRPC cria um cliente JSON-RPC 2.0 para o endpoint path.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/rpc")
+//
+// Exemplo:
+//
+//	rpc := m.JSONRPC("/rpc")
+func (m *model) JSONRPC(path string) *rpcClient {
+	return &rpcClient{m: m, path: path}
+}
+
+// id retorna o próximo id das requisições.
+func (c *rpcClient) id() *uint64 {
+	id := c.nextID.Add(1)
+	return &id
+}
+
+// Call chama method e decodifica "result" em result.
+//
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - method: Nome do método (ex: "eth_getBalance")
+//   - params: Parâmetros (slice para posicionais, struct ou map para nomeados), ou nil
+//   - result: Ponteiro que receberá o conteúdo de "result", ou nil
+//   - opts: Opções da chamada (ex: WithHeader)
+//
+// Exemplo:
+//
+//	var balance string
+//	err := rpc.Call(ctx, "eth_getBalance", []interface{}{addr, "latest"}, &balance)
+//
+// Retorna:
+//   - *httpError: Erro HTTP ou JSON-RPC (causa *RPCError), nil caso contrário
+func (c *rpcClient) Call(ctx context.Context, method string, params interface{}, result interface{}, opts ...CallOption) *httpError {
+	options := newCallOptions(opts)
+	req := rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: c.id()}
+
+	data, status, httpErr := c.post(ctx, req, opts)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var resp rpcResponse
+	if err := json.Unmarshal(data, &resp); err != nil || (resp.Result == nil && resp.Error == nil) {
+		return c.invalidResponse(status, err)
+	}
+	return c.finish(resp, status, result, options)
+}
+
+// Notify envia uma notificação (requisição sem id), para a qual o servidor não responde.
+//
+// Parâmetros:
+//   - ctx: Contexto da chamada
+//   - method: Nome do método
+//   - params: Parâmetros, ou nil
+//   - opts: Opções da chamada
+//
+// Retorna:
+//   - *httpError: Erro HTTP se o envio falhar, nil caso contrário
+func (c *rpcClient) Notify(ctx context.Context, method string, params interface{}, opts ...CallOption) *httpError {
+	_, status, httpErr := c.post(ctx, rpcRequest{JSONRPC: "2.0", Method: method, Params: params}, opts)
+	if httpErr != nil {
+		return httpErr
+	}
+	if status >= 400 {
+		return c.m.MakeError(status, http.StatusText(status), "Status code >= 400")
+	}
+	return nil
+}
+
+// Batch cria um lote de chamadas enviadas em uma única requisição HTTP.
+//
+// Exemplo:
+//
+//	batch := rpc.Batch()
+//	balance := batch.Call("eth_getBalance", []interface{}{addr, "latest"}, &wei)
+//	count := batch.Call("eth_getTransactionCount", []interface{}{addr, "latest"}, &nonce)
+//	batch.Notify("log_event", map[string]string{"event": "sync"})
+//	if err := batch.Send(ctx); err != nil {
+//	    return err
+//	}
+//	if err := balance.Err(); err != nil {
+//	    // ...
+//	}
+func (c *rpcClient) Batch() *rpcBatch {
+	return &rpcBatch{client: c}
+}
+
+// post envia o corpo JSON e lê a resposta. Retorna o corpo e o status HTTP.
+func (c *rpcClient) post(ctx context.Context, body interface{}, opts []CallOption) ([]byte, int, *httpError) {
+	options := newCallOptions(append([]CallOption{
+		WithContentType("application/json"),
+		WithHeader("Accept", "application/json"),
+	}, append(opts, WithContext(ctx))...))
+
+	payload := body
+	resp, httpErr := c.m.exchange(http.MethodPost, c.path, &payload, options)
+	if httpErr != nil {
+		return nil, 0, httpErr
+	}
+	defer resp.Body.Close()
+
+	data, httpErr := c.m.readBody(resp, options)
+	if httpErr != nil {
+		return nil, 0, httpErr
+	}
+	return data, resp.StatusCode, nil
+}
+
+// finish decodifica "result" em dest ou converte "error" em HttpError.
+func (c *rpcClient) finish(resp rpcResponse, status int, dest interface{}, options *callOptions) *httpError {
+	m := c.m
+	if resp.Error != nil {
+		return m.wrapError(bodyErrorStatus(status), resp.Error, resp.Error.Error())
+	}
+	if dest != nil {
+		if err := decodeInto("application/json", bytes.NewReader(resp.Result), dest, options); err != nil {
+			if options.disallowUnknownFields {
+				return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 08")
+			}
+			log.Println(err.Error())
+		}
+	}
+	return nil
+}
+
+// invalidResponse cria o erro de uma resposta que não é JSON-RPC.
+func (c *rpcClient) invalidResponse(status int, err error) *httpError {
+	if status >= 400 {
+		return c.m.MakeError(status, http.StatusText(status), "Status code >= 400")
+	}
+	if err == nil {
+		err = errors.New("jsonrpc: resposta sem result e sem error")
+	}
+	return c.m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+}
+
+// rpcBatch é um lote de chamadas JSON-RPC. As respostas são associadas às
+// chamadas pelo id, independentemente da ordem em que o servidor as devolve.
+type rpcBatch struct {
+	client   *rpcClient
+	requests []rpcRequest
+	calls    []*rpcCall
+}
+
+// rpcCall é uma chamada de um lote. O resultado fica disponível depois de Send.
+type rpcCall struct {
+	id   string
+	dest interface{}
+	err  *httpError
+	done bool
+}
+
+// Err retorna o erro da chamada (causa *RPCError quando o servidor retorna "error"),
+// ou nil quando "result" foi decodificado com sucesso.
+func (c *rpcCall) Err() error {
+	if !c.done {
+		return errors.New("jsonrpc: lote ainda não enviado")
+	}
+	if c.err == nil {
+		return nil
+	}
+	return c.err
+}
+
+// Call adiciona uma chamada ao lote; "result" é decodificado em result no Send.
+//
+// Retorna a chamada, para consultar o erro depois de Send.
+func (b *rpcBatch) Call(method string, params interface{}, result interface{}) *rpcCall {
+	id := b.client.id()
+	b.requests = append(b.requests, rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+	call := &rpcCall{id: strconv.FormatUint(*id, 10), dest: result}
+	b.calls = append(b.calls, call)
+	return call
+}
+
+// Notify adiciona uma notificação ao lote.
+//
+// Retorna o próprio lote para permitir encadeamento de métodos.
+func (b *rpcBatch) Notify(method string, params interface{}) *rpcBatch {
+	b.requests = append(b.requests, rpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+	return b
+}
+
+// Send envia o lote. Cada chamada recebe o seu resultado ou erro, consultado com Err.
+// Chamadas sem resposta no lote recebem um erro.
+//
+// Retorna:
+//   - *httpError: Erro HTTP se o lote não puder ser enviado ou a resposta for inválida
+func (b *rpcBatch) Send(ctx context.Context, opts ...CallOption) *httpError {
+	m := b.client.m
+	if len(b.requests) == 0 {
+		return nil
+	}
+	options := newCallOptions(opts)
+
+	data, status, httpErr := b.client.post(ctx, b.requests, opts)
+	if httpErr != nil {
+		return httpErr
+	}
+	if len(b.calls) == 0 {
+		// Only notifications: the server sends no response
+		if status >= 400 {
+			return m.MakeError(status, http.StatusText(status), "Status code >= 400")
+		}
+		return nil
+	}
+
+	var responses []rpcResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		// A rejected batch is answered with a single error response
+		var single rpcResponse
+		if json.Unmarshal(data, &single) != nil || single.Error == nil {
+			return b.client.invalidResponse(status, err)
+		}
+		for _, call := range b.calls {
+			call.done = true
+			call.err = m.wrapError(bodyErrorStatus(status), single.Error, single.Error.Error())
+		}
+		return nil
+	}
+
+	byID := make(map[string]rpcResponse, len(responses))
+	for _, resp := range responses {
+		byID[string(bytes.Trim(resp.ID, `"`))] = resp
+	}
+	for _, call := range b.calls {
+		call.done = true
+		resp, ok := byID[call.id]
+		if !ok || (resp.Result == nil && resp.Error == nil) {
+			call.err = m.wrapError(http.StatusInternalServerError, fmt.Errorf("jsonrpc: resposta ausente para o id %s", call.id), "Houve um erro interno no servidor! C: 12")
+			continue
+		}
+		call.err = b.client.finish(resp, status, call.dest, options)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package lapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// multipartPart representa uma parte do corpo multipart/form-data.
+type multipartPart struct {
+	// header contém os headers da parte (Content-Disposition, Content-Type, etc).
+	header textproto.MIMEHeader
+
+	// open abre o conteúdo da parte. Pode ser chamada mais de uma vez quando
+	// o corpo é reenviado.
+	open func() (io.ReadCloser, error)
+
+	// replayable indica se open pode ser chamada novamente.
+	replayable bool
+}
+
+// multipartBuilder constrói um corpo multipart/form-data com campos e arquivos.
+// O conteúdo é transmitido por um pipe durante o envio, sem ser carregado em memória.
+//
+// Exemplo de uso:
+//
+//	mp := NewMultipart().
+//	    Field("title", "Relatório").
+//	    File("file", "/tmp/relatorio.pdf").
+//	    JSON("metadata", map[string]string{"owner": "john"})
+//	err := m.Post("/uploads", nil, &dest, WithMultipart(mp))
+//
+//	// Ou fora do contexto
+//	r := OutOfContext().SetMultipart(mp)
+type multipartBuilder struct {
+	boundary string
+	parts    []*multipartPart
+	err      error
+}
+
+// NewMultipart cria um novo construtor de corpo multipart/form-data.
+//
+// Exemplo:
+//
+//	mp := NewMultipart().Field("name", "John").File("avatar", "avatar.png")
+func NewMultipart() *multipartBuilder {
+	return &multipartBuilder{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// Field adiciona um campo de formulário.
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) Field(name, value string) *multipartBuilder {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(name)))
+	return b.add(header, bytesOpener([]byte(value)), true)
+}
+
+// File adiciona um arquivo lido do disco.
+// O arquivo é aberto novamente a cada envio, o que permite reenviar o corpo.
+//
+// Exemplo:
+//
+//	mp.File("document", "/tmp/contrato.pdf")
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) File(field, path string) *multipartBuilder {
+	if _, err := os.Stat(path); err != nil {
+		b.setErr(err)
+		return b
+	}
+	open := func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	return b.add(fileHeader(field, filepath.Base(path)), open, true)
+}
+
+// FileFS adiciona um arquivo lido de um fs.FS (ex: embed.FS, os.DirFS).
+//
+// Exemplo:
+//
+//	mp.FileFS("template", templates, "contrato.html")
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) FileFS(field string, fsys fs.FS, name string) *multipartBuilder {
+	if _, err := fs.Stat(fsys, name); err != nil {
+		b.setErr(err)
+		return b
+	}
+	open := func() (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}
+	return b.add(fileHeader(field, filepath.Base(name)), open, true)
+}
+
+// FileReader adiciona um arquivo a partir de um io.Reader.
+// O corpo só pode ser reenviado quando r também implementa io.Seeker.
+//
+// Exemplo:
+//
+//	mp.FileReader("photo", "foto.jpg", resp.Body)
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) FileReader(field, filename string, r io.Reader) *multipartBuilder {
+	open, replayable := readerOpener(r)
+	return b.add(fileHeader(field, filename), open, replayable)
+}
+
+// JSON adiciona uma parte com o valor codificado em JSON (Content-Type: application/json).
+//
+// Exemplo:
+//
+//	mp.JSON("metadata", map[string]interface{}{"tags": []string{"a", "b"}})
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) JSON(name string, v interface{}) *multipartBuilder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(name)))
+	header.Set("Content-Type", "application/json")
+	return b.add(header, bytesOpener(data), true)
+}
+
+// Part adiciona uma parte com headers definidos pelo chamador.
+// O corpo só pode ser reenviado quando r também implementa io.Seeker.
+//
+// Exemplo:
+//
+//	header := textproto.MIMEHeader{}
+//	header.Set("Content-Disposition", `form-data; name="raw"`)
+//	mp.Part(header, strings.NewReader("conteúdo"))
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) Part(header textproto.MIMEHeader, r io.Reader) *multipartBuilder {
+	open, replayable := readerOpener(r)
+	return b.add(header, open, replayable)
+}
+
+// WithHeader define um header na última parte adicionada.
+//
+// Exemplo:
+//
+//	mp.File("image", "foto.png").WithHeader("Content-Type", "image/png")
+//
+// Retorna o próprio construtor para permitir encadeamento de métodos.
+func (b *multipartBuilder) WithHeader(key, value string) *multipartBuilder {
+	if len(b.parts) == 0 {
+		b.setErr(fmt.Errorf("lapi: WithHeader(%q) chamado antes de adicionar uma parte", key))
+		return b
+	}
+	b.parts[len(b.parts)-1].header.Set(key, value)
+	return b
+}
+
+// Err retorna o primeiro erro ocorrido durante a construção do corpo.
+func (b *multipartBuilder) Err() error {
+	return b.err
+}
+
+// ContentType retorna o valor do header Content-Type, incluindo o boundary.
+func (b *multipartBuilder) ContentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// Replayable indica se o corpo pode ser gerado novamente para reenvio.
+func (b *multipartBuilder) Replayable() bool {
+	for _, part := range b.parts {
+		if !part.replayable {
+			return false
+		}
+	}
+	return true
+}
+
+// Reader retorna um novo leitor do corpo multipart.
+// As partes são escritas em um pipe por uma goroutine à medida que o corpo é lido.
+func (b *multipartBuilder) Reader() (io.ReadCloser, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(b.write(pw))
+	}()
+	return pr, nil
+}
+
+// source retorna a fonte do corpo usada no envio da requisição.
+func (b *multipartBuilder) source() *bodySource {
+	return &bodySource{
+		open:        b.Reader,
+		replayable:  b.Replayable(),
+		contentType: b.ContentType(),
+	}
+}
+
+// write escreve todas as partes em w.
+func (b *multipartBuilder) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range b.parts {
+		dst, err := mw.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+		src, err := part.open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// add adiciona uma parte ao corpo.
+func (b *multipartBuilder) add(header textproto.MIMEHeader, open func() (io.ReadCloser, error), replayable bool) *multipartBuilder {
+	b.parts = append(b.parts, &multipartPart{
+		header:     header,
+		open:       open,
+		replayable: replayable,
+	})
+	return b
+}
+
+// setErr registra o primeiro erro de construção.
+func (b *multipartBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// fileHeader monta os headers de uma parte de arquivo.
+func fileHeader(field, filename string) textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field), escapeQuotes(filename)))
+	header.Set("Content-Type", "application/octet-stream")
+	return header
+}
+
+// bytesOpener retorna uma função que abre um leitor sobre data.
+func bytesOpener(data []byte) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// readerOpener retorna uma função que abre r. Quando r implementa io.Seeker,
+// ele é reposicionado no início a cada abertura e o corpo pode ser reenviado.
+func readerOpener(r io.Reader) (func() (io.ReadCloser, error), bool) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		used := false
+		return func() (io.ReadCloser, error) {
+			if used {
+				return nil, errBodyNotReplayable
+			}
+			used = true
+			return io.NopCloser(r), nil
+		}, false
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	return func() (io.ReadCloser, error) {
+		if err != nil {
+			return nil, err
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}, err == nil
+}
+
+// quoteEscaper escapa os caracteres que não podem aparecer entre aspas em Content-Disposition.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapa aspas e barras invertidas.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,115 @@
+package lapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// WithMaxLineSize define o tamanho máximo, em bytes, de uma linha das respostas NDJSON.
+// Por padrão, 1 MiB.
+//
+// Exemplo:
+//
+//	for row, err := range NDJSON[Row](m, "/export", WithMaxLineSize(8<<20)) {
+//	    // ...
+//	}
+func WithMaxLineSize(size int) CallOption {
+	return func(o *callOptions) {
+		o.maxLineSize = size
+	}
+}
+
+// NDJSON faz uma requisição GET para path e decodifica a resposta NDJSON
+// (JSON Lines) linha a linha, sem carregá-la em memória.
+//
+// Linhas em branco são ignoradas. Um erro de decodificação é entregue com o número
+// da linha e a iteração continua na linha seguinte; erros de status, de leitura
+// ou de linha maior que o limite encerram a iteração. Interromper o laço fecha a conexão.
+// O timeout do cliente não se aplica; use WithContext para limitar a duração.
+//
+// Parâmetros:
+//   - m: Modelo com a configuração do cliente
+//   - path: Caminho do endpoint (ex: "/logs/export")
+//   - opts: Opções da chamada (ex: WithContext, WithMaxLineSize, WithDisallowUnknownFields)
+//
+// Exemplo:
+//
+//	for entry, err := range NDJSON[LogEntry](m, "/logs/export", WithContext(ctx)) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(entry.Message)
+//	}
+func NDJSON[T any](m *model, path string, opts ...CallOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		options := newCallOptions(append([]CallOption{WithHeader("Accept", "application/x-ndjson, application/jsonl")}, opts...))
+		options.stream = true
+		resp, httpErr := m.exchange(http.MethodGet, path, nil, options)
+		if httpErr != nil {
+			yield(zero, httpErr)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			yield(zero, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400"))
+			return
+		}
+
+		download := newProgressTracker(options.download)
+		defer download.finish()
+
+		maxLineSize := options.maxLineSize
+		if maxLineSize <= 0 {
+			maxLineSize = defaultMaxLineSize
+		}
+		scanner := bufio.NewScanner(limitBody(trackDownload(download, resp.Body, 0, contentLength(resp)), options.bodyLimit(m.request.maxBodySize)))
+		scanner.Buffer(make([]byte, 0, min(64<<10, maxLineSize)), maxLineSize)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			var item T
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			if options.disallowUnknownFields {
+				decoder.DisallowUnknownFields()
+			}
+			if options.useNumber {
+				decoder.UseNumber()
+			}
+			err := decoder.Decode(&item)
+			// The line is trimmed, so a single value must consume all of it
+			if err == nil && decoder.InputOffset() != int64(len(data)) {
+				err = errors.New("dados após o valor JSON")
+			}
+			if err != nil {
+				if !yield(zero, fmt.Errorf("lapi: linha %d: %w", line, err)) {
+					return
+				}
+				continue
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				err = fmt.Errorf("lapi: linha %d excede %d bytes", line+1, maxLineSize)
+			}
+			yield(zero, err)
+		}
+	}
+}
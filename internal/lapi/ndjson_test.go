@@ -0,0 +1,34 @@
+package lapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONTrailingData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"a\":1}\n{\"a\":2}}\n{\"a\":3} {\"a\":4}\n  {\"a\":5}  \n")
+	}))
+	defer srv.Close()
+
+	var values []int
+	var errs []string
+	for v, err := range NDJSON[map[string]int](NewRequest(srv.URL, nil, 5), "/") {
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		values = append(values, v["a"])
+	}
+
+	if len(values) != 2 || values[0] != 1 || values[1] != 5 {
+		t.Errorf("valores = %v, want [1 5]", values)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], "linha 2") || !strings.Contains(errs[1], "linha 3") {
+		t.Errorf("erros = %q, esperados nas linhas 2 e 3", errs)
+	}
+}
@@ -0,0 +1,410 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OperationStatus é o estado de uma operação de longa duração, obtido a cada consulta.
+type OperationStatus struct {
+	// StatusCode é o status HTTP da consulta.
+	StatusCode int
+
+	// Header são os headers da resposta da consulta.
+	Header http.Header
+
+	// Body é o corpo da resposta da consulta.
+	Body []byte
+
+	// Status é o valor do campo de estado reconhecido no corpo (ex: "Running",
+	// "Succeeded"), ou vazio quando não há um.
+	Status string
+}
+
+// OperationError indica que a operação terminou em um estado de falha.
+// É a causa do HttpError retornado por Wait e pode ser obtido com errors.As.
+type OperationError struct {
+	// Status é o estado final informado pelo servidor (ex: "Failed").
+	Status string
+
+	// Body é o corpo da última consulta, com os detalhes da falha.
+	Body []byte
+}
+
+// Error implementa a interface error do Go.
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("lapi: operação terminou com estado %q", e.Status)
+}
+
+// ErrOperationFailed é retornado pelas funções de WithTerminal para indicar
+// uma falha sem detalhes adicionais.
+var ErrOperationFailed = errors.New("lapi: operação falhou")
+
+// maxOperationFailures é a quantidade de consultas seguidas com falha transitória antes de desistir.
+const maxOperationFailures = 5
+
+// operation acompanha uma operação assíncrona que responde 202 Accepted com um
+// header Location ou Operation-Location, consultando-o até um estado final.
+//
+// Exemplo de uso:
+//
+//	var export Export
+//	err := m.Operation("POST", "/exports", &payload).
+//	    WithPollInterval(time.Second, 30*time.Second).
+//	    Wait(ctx, &export)
+type operation struct {
+	m       *model
+	method  string
+	path    string
+	payload *interface{}
+	opts    []CallOption
+
+	interval    time.Duration
+	maxInterval time.Duration
+	terminal    func(OperationStatus) (bool, error)
+}
+
+// Operation prepara uma requisição que inicia uma operação de longa duração.
+// A requisição só é enviada por Wait.
+//
+// Parâmetros:
+//   - method: Método HTTP (ex: "POST")
+//   - path: Caminho do endpoint (ex: "/exports")
+//   - payload: Corpo da requisição, ou nil
+//   - opts: Opções aplicadas à requisição e às consultas (ex: WithHeader)
+//
+// Exemplo:
+//
+//	op := m.Operation("POST", "/reports", &payload)
+func (m *model) Operation(method, path string, payload *interface{}, opts ...CallOption) *operation {
+	return &operation{
+		m:           m,
+		method:      method,
+		path:        path,
+		payload:     payload,
+		opts:        opts,
+		interval:    time.Second,
+		maxInterval: 30 * time.Second,
+	}
+}
+
+// WithPollInterval define o intervalo entre as consultas, dobrado a cada consulta
+// até max. Um header Retry-After do servidor tem prioridade. Por padrão, 1s e 30s.
+// Valores zero ou negativos usam o padrão, e max menor que initial passa a ser initial.
+//
+// Retorna a própria operação para permitir encadeamento de métodos.
+func (o *operation) WithPollInterval(initial, max time.Duration) *operation {
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if max < initial {
+		max = initial
+	}
+	o.interval = initial
+	o.maxInterval = max
+	return o
+}
+
+// WithTerminal define como reconhecer o fim da operação. A função retorna true
+// quando a operação terminou e um erro quando terminou com falha.
+// Sem ela, são usados os campos "status", "state", "provisioningState" e "done" do corpo.
+//
+// Exemplo:
+//
+//	op.WithTerminal(func(s OperationStatus) (bool, error) {
+//	    switch s.Status {
+//	    case "ready":
+//	        return true, nil
+//	    case "rejected":
+//	        return true, ErrOperationFailed
+//	    }
+//	    return false, nil
+//	})
+//
+// Retorna a própria operação para permitir encadeamento de métodos.
+func (o *operation) WithTerminal(fn func(OperationStatus) (bool, error)) *operation {
+	o.terminal = fn
+	return o
+}
+
+// Wait envia a requisição e, se o servidor responder 202 Accepted, consulta o
+// endereço de Operation-Location (ou Location) até a operação terminar. O recurso
+// final é decodificado em dest: o campo "resourceLocation" ou o Location da resposta
+// inicial, quando informados, são consultados; caso contrário, é usado o corpo da
+// última consulta (ou o seu campo "response"). Respostas 2xx diferentes de 202
+// são decodificadas diretamente em dest.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a duração total da operação
+//   - dest: Ponteiro que receberá o recurso final, ou nil
+//
+// Retorna:
+//   - *httpError: Erro HTTP, falha da operação (causa *OperationError) ou prazo expirado
+func (o *operation) Wait(ctx context.Context, dest interface{}) *httpError {
+	m := o.m
+	options := newCallOptions(append(o.opts, WithContext(ctx)))
+
+	resp, httpErr := m.exchange(o.method, o.path, o.payload, options)
+	if httpErr != nil {
+		return httpErr
+	}
+	status := o.status(resp, options)
+	if status.err != nil {
+		return status.err
+	}
+	if resp.StatusCode >= 400 {
+		return m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return o.decode(status, dest, options)
+	}
+
+	pollURL := resp.Header.Get("Operation-Location")
+	if pollURL == "" {
+		pollURL = resp.Header.Get("Location")
+	}
+	if pollURL == "" {
+		return m.wrapError(http.StatusInternalServerError, errors.New("lapi: resposta 202 sem Location ou Operation-Location"), "Houve um erro interno no servidor! C: 12")
+	}
+	poll, err := sameOrigin(resp.Request.URL, pollURL)
+	if err != nil {
+		return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+	}
+	var location *url.URL
+	if resp.Header.Get("Operation-Location") != "" && resp.Header.Get("Location") != "" {
+		location, _ = sameOrigin(resp.Request.URL, resp.Header.Get("Location"))
+	}
+
+	delay := o.interval
+	failures := 0
+	for {
+		wait := delay
+		if d, ok := retryAfter(status.Header); ok {
+			wait = d
+		} else if delay *= 2; delay > o.maxInterval {
+			delay = o.maxInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return m.wrapError(http.StatusRequestTimeout, ctx.Err(), "Tempo limite da operação excedido")
+		case <-timer.C:
+		}
+
+		status = o.get(ctx, poll, options)
+		if status.err != nil {
+			if ctx.Err() != nil {
+				return m.wrapError(http.StatusRequestTimeout, ctx.Err(), "Tempo limite da operação excedido")
+			}
+			return status.err
+		}
+		if transientStatus(status.StatusCode) {
+			if failures++; failures >= maxOperationFailures {
+				return m.MakeError(status.StatusCode, http.StatusText(status.StatusCode), "Status code >= 400")
+			}
+			continue
+		}
+		failures = 0
+		if status.StatusCode >= 400 {
+			return m.MakeError(status.StatusCode, http.StatusText(status.StatusCode), "Status code >= 400")
+		}
+
+		done, err := o.isTerminal(status.OperationStatus)
+		if err != nil {
+			opErr := &OperationError{Status: status.Status, Body: status.Body}
+			if !errors.Is(err, ErrOperationFailed) {
+				return m.wrapError(status.StatusCode, fmt.Errorf("%w: %w", opErr, err), opErr.Error())
+			}
+			return m.wrapError(status.StatusCode, opErr, opErr.Error())
+		}
+		if !done {
+			continue
+		}
+
+		// Fetch the final resource when the server points to it
+		if target := jsonString(status.Body, "resourceLocation"); target != "" {
+			location, err = sameOrigin(poll, target)
+			if err != nil {
+				return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+			}
+		}
+		if location != nil && dest != nil {
+			status = o.get(ctx, location, options)
+			if status.err != nil {
+				return status.err
+			}
+			if status.StatusCode >= 400 {
+				return m.MakeError(status.StatusCode, http.StatusText(status.StatusCode), "Status code >= 400")
+			}
+		} else if response := jsonField(status.Body, "response"); response != nil {
+			status.Body = response
+			status.contentType = "application/json"
+		}
+		return o.decode(status, dest, options)
+	}
+}
+
+// operationResponse é uma resposta lida durante o acompanhamento da operação.
+type operationResponse struct {
+	OperationStatus
+	contentType string
+	err         *httpError
+}
+
+// status lê a resposta e identifica o estado da operação.
+func (o *operation) status(resp *http.Response, options *callOptions) operationResponse {
+	defer resp.Body.Close()
+
+	data, httpErr := o.m.readBody(resp, options)
+	if httpErr != nil {
+		return operationResponse{err: httpErr}
+	}
+	return operationResponse{
+		OperationStatus: OperationStatus{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       data,
+			Status:     operationState(data),
+		},
+		contentType: resp.Header.Get("Content-Type"),
+	}
+}
+
+// get consulta target com os headers e a autenticação do modelo.
+func (o *operation) get(ctx context.Context, target *url.URL, options *callOptions) operationResponse {
+	m := o.m
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return operationResponse{err: m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 02")}
+	}
+	setHeaders(req, m.request.headers, "")
+	for key, value := range options.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := m.send(req)
+	if err != nil {
+		return operationResponse{err: m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 03")}
+	}
+	return o.status(resp, options)
+}
+
+// isTerminal indica se a operação terminou, usando WithTerminal ou os campos de estado comuns.
+func (o *operation) isTerminal(status OperationStatus) (bool, error) {
+	if o.terminal != nil {
+		return o.terminal(status)
+	}
+	if status.StatusCode == http.StatusAccepted {
+		return false, nil
+	}
+
+	switch strings.ToLower(status.Status) {
+	case "succeeded", "success", "successful", "completed", "complete", "done", "finished":
+		return true, nil
+	case "failed", "failure", "error", "canceled", "cancelled", "aborted":
+		return true, ErrOperationFailed
+	case "":
+		// Google style: {"done": true, "error": {...}} or {"done": true, "response": {...}}
+		var body struct {
+			Done  *bool           `json:"done"`
+			Error json.RawMessage `json:"error"`
+		}
+		if json.Unmarshal(status.Body, &body) == nil && body.Done != nil {
+			if !*body.Done {
+				return false, nil
+			}
+			if len(body.Error) > 0 && !bytes.Equal(body.Error, []byte("null")) {
+				return true, ErrOperationFailed
+			}
+			return true, nil
+		}
+		// No state field: the response is the final resource
+		return true, nil
+	}
+	return false, nil
+}
+
+// decode decodifica o corpo da resposta final em dest.
+func (o *operation) decode(status operationResponse, dest interface{}, options *callOptions) *httpError {
+	m := o.m
+	if len(status.Body) == 0 {
+		return nil
+	}
+	if err := decodeInto(status.contentType, bytes.NewReader(status.Body), dest, options); err != nil {
+		if options.disallowUnknownFields {
+			return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 08")
+		}
+		log.Println(err.Error())
+	}
+	return nil
+}
+
+// operationState retorna o valor do campo de estado do corpo JSON, ou vazio.
+func operationState(data []byte) string {
+	for _, key := range []string{"status", "state", "provisioningState"} {
+		if value := jsonString(data, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// jsonField retorna o valor bruto de um campo de primeiro nível do objeto JSON, ou nil.
+func jsonField(data []byte, key string) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil
+	}
+	return fields[key]
+}
+
+// jsonString retorna um campo texto de primeiro nível do objeto JSON, ou vazio.
+func jsonString(data []byte, key string) string {
+	var value string
+	if raw := jsonField(data, key); raw != nil && json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	return ""
+}
+
+// sameOrigin resolve ref em relação a base e exige o mesmo esquema e host,
+// para que os headers e o token do modelo não sejam enviados a outro servidor.
+func sameOrigin(base *url.URL, ref string) (*url.URL, error) {
+	target, err := base.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != base.Scheme || target.Host != base.Host {
+		return nil, fmt.Errorf("lapi: endereço da operação em outra origem: %s", target.Redacted())
+	}
+	return target, nil
+}
+
+// retryAfter interpreta o header Retry-After, em segundos ou como data HTTP.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,191 @@
+package lapi
+
+import "context"
+
+// CallOption é uma opção aplicada a uma única chamada dos verbos do modelo
+// (Get, Post, Put, Delete, Patch e MakeRequest).
+//
+// Exemplo de uso:
+//
+//	err := m.Post("/users", &payload, &dest, WithContentType("application/xml"))
+type CallOption func(*callOptions)
+
+// callOptions contém as configurações de uma única chamada.
+type callOptions struct {
+	// contentType é o media type usado para codificar o payload.
+	// Quando vazio, é usado o Content-Type dos headers ou, na falta dele, JSON.
+	contentType string
+
+	// multipart é o corpo multipart/form-data da chamada.
+	// Quando definido, substitui o payload.
+	multipart *multipartBuilder
+
+	// maxBodySize é o tamanho máximo do corpo da resposta da chamada.
+	// Quando zero, é usado o limite do modelo.
+	maxBodySize int64
+
+	// disallowUnknownFields faz a decodificação JSON falhar com campos desconhecidos.
+	disallowUnknownFields bool
+
+	// useNumber decodifica números JSON como json.Number em vez de float64.
+	useNumber bool
+
+	// raw recebe os bytes brutos do corpo da resposta, quando definido.
+	raw *[]byte
+
+	// headers são headers adicionais enviados apenas nesta chamada.
+	headers map[string]string
+
+	// checksum é o SHA-256 esperado do conteúdo baixado, em hexadecimal.
+	checksum string
+
+	// contentDigest indica se o header Content-Digest da resposta deve ser verificado.
+	contentDigest bool
+
+	// resume indica se o DownloadFile deve ser retomável.
+	resume bool
+
+	// retries é a quantidade de retomadas automáticas do download.
+	retries int
+
+	// upload e download reportam o progresso do envio e do recebimento dos corpos.
+	upload   *progressConfig
+	download *progressConfig
+
+	// ctx é o contexto da requisição, usado para cancelamento e prazos.
+	ctx context.Context
+
+	// stream indica uma resposta de longa duração, que não usa o timeout do cliente.
+	stream bool
+
+	// maxLineSize é o tamanho máximo de uma linha das respostas NDJSON.
+	maxLineSize int
+
+	// operationName é o nome da operação GraphQL executada.
+	operationName string
+
+	// csvDelimiter é o separador das colunas CSV; zero detecta no cabeçalho.
+	csvDelimiter rune
+}
+
+// newCallOptions aplica as opções informadas sobre as configurações padrão.
+func newCallOptions(opts []CallOption) *callOptions {
+	options := &callOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithContext define o contexto da chamada. Quando o contexto é cancelado
+// ou o prazo expira, a requisição e a leitura da resposta são interrompidas.
+//
+// Exemplo:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	err := m.Get("/users", &dest, WithContext(ctx))
+func WithContext(ctx context.Context) CallOption {
+	return func(o *callOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithHeader define um header enviado apenas nesta chamada.
+// Substitui o header de mesmo nome definido no modelo.
+//
+// Exemplo:
+//
+//	err := m.Get("/users", &dest, WithHeader("X-Request-ID", id))
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[key] = value
+	}
+}
+
+// WithContentType define o media type usado para codificar o payload da chamada.
+// O codec correspondente deve estar registrado (ver RegisterCodec).
+//
+// Exemplo:
+//
+//	err := m.Post("/login", &payload, &dest, WithContentType("application/x-www-form-urlencoded"))
+func WithContentType(mediaType string) CallOption {
+	return func(o *callOptions) {
+		o.contentType = mediaType
+	}
+}
+
+// WithMultipart define o corpo da chamada como multipart/form-data.
+// O payload da chamada é ignorado.
+//
+// Exemplo:
+//
+//	mp := NewMultipart().Field("name", "John").File("avatar", "avatar.png")
+//	err := m.Post("/users", nil, &dest, WithMultipart(mp))
+func WithMultipart(body *multipartBuilder) CallOption {
+	return func(o *callOptions) {
+		o.multipart = body
+	}
+}
+
+// WithMaxBodySize define o tamanho máximo, em bytes, do corpo da resposta da chamada.
+// Substitui o limite definido com SetMaxBodySize. Ao ultrapassá-lo, a chamada
+// falha com um erro que corresponde a ErrBodyTooLarge.
+//
+// Exemplo:
+//
+//	err := m.Get("/report", &dest, WithMaxBodySize(50<<20))
+func WithMaxBodySize(size int64) CallOption {
+	return func(o *callOptions) {
+		o.maxBodySize = size
+	}
+}
+
+// WithDisallowUnknownFields faz a decodificação JSON da resposta falhar quando
+// existirem campos que não correspondem a dest. O erro é retornado pela chamada.
+//
+// Exemplo:
+//
+//	err := m.Get("/users/1", &user, WithDisallowUnknownFields())
+func WithDisallowUnknownFields() CallOption {
+	return func(o *callOptions) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// WithUseNumber decodifica os números JSON da resposta como json.Number em vez de float64.
+//
+// Exemplo:
+//
+//	var dest map[string]interface{}
+//	err := m.Get("/balance", &dest, WithUseNumber())
+func WithUseNumber() CallOption {
+	return func(o *callOptions) {
+		o.useNumber = true
+	}
+}
+
+// WithRawBody guarda em raw os bytes brutos do corpo da resposta, além de decodificá-lo em dest.
+// Por padrão, o corpo é decodificado sem ser mantido em memória.
+//
+// Exemplo:
+//
+//	var raw []byte
+//	err := m.Get("/users/1", &user, WithRawBody(&raw))
+func WithRawBody(raw *[]byte) CallOption {
+	return func(o *callOptions) {
+		o.raw = raw
+	}
+}
+
+// bodyLimit retorna o tamanho máximo do corpo da resposta da chamada,
+// usando limit (o limite do modelo) quando nenhum foi definido por WithMaxBodySize.
+func (o *callOptions) bodyLimit(limit int64) int64 {
+	if o.maxBodySize != 0 {
+		return o.maxBodySize
+	}
+	return limit
+}
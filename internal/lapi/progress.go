@@ -0,0 +1,333 @@
+package lapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Progress contém o estado de uma transferência (envio ou recebimento de corpo).
+type Progress struct {
+	// Bytes é a quantidade de bytes transferidos.
+	Bytes int64
+
+	// Total é o tamanho total em bytes, ou -1 quando desconhecido.
+	Total int64
+
+	// Rate é a taxa instantânea, em bytes por segundo, desde o último relatório.
+	Rate float64
+
+	// AverageRate é a taxa média, em bytes por segundo, desde o início da transferência.
+	AverageRate float64
+
+	// ETA é o tempo restante estimado pela taxa média, ou -1 quando desconhecido.
+	ETA time.Duration
+
+	// Elapsed é o tempo decorrido desde o início da transferência.
+	Elapsed time.Duration
+
+	// Done indica o último relatório da transferência.
+	Done bool
+}
+
+// ProgressFunc recebe os relatórios de progresso de uma transferência.
+// As chamadas são sequenciais, feitas por uma única goroutine, e nunca bloqueiam
+// a transferência: se a função demorar, os relatórios intermediários são descartados
+// e apenas o mais recente é entregue. O relatório final (Done) é sempre entregue
+// antes de a chamada que originou a transferência retornar.
+//
+// Exemplo de uso:
+//
+//	err := m.Post("/uploads", nil, &dest,
+//	    WithMultipart(mp),
+//	    WithUploadProgress(func(p Progress) {
+//	        fmt.Printf("\r%d/%d bytes (%.0f KB/s, ETA %s)", p.Bytes, p.Total, p.Rate/1024, p.ETA)
+//	    }, 200*time.Millisecond),
+//	)
+type ProgressFunc func(Progress)
+
+// defaultProgressInterval é o intervalo padrão entre relatórios de progresso.
+const defaultProgressInterval = 100 * time.Millisecond
+
+// progressConfig é a configuração de progresso de uma direção da transferência.
+type progressConfig struct {
+	fn       ProgressFunc
+	interval time.Duration
+}
+
+// WithUploadProgress reporta o progresso do envio do corpo da requisição da chamada
+// (payload, SetBody ou multipart). Em reenvios (ex: renovação do token), a contagem recomeça.
+//
+// Parâmetros:
+//   - fn: Função que recebe os relatórios
+//   - interval: Intervalo mínimo entre relatórios (zero usa 100ms)
+//
+// Exemplo:
+//
+//	err := m.Post("/uploads", nil, &dest, WithMultipart(mp), WithUploadProgress(bar.Update, time.Second))
+func WithUploadProgress(fn ProgressFunc, interval time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.upload = &progressConfig{fn: fn, interval: interval}
+	}
+}
+
+// WithDownloadProgress reporta o progresso do recebimento do corpo da resposta da
+// chamada (decodificação em dest, Download e DownloadFile).
+//
+// Parâmetros:
+//   - fn: Função que recebe os relatórios
+//   - interval: Intervalo mínimo entre relatórios (zero usa 100ms)
+//
+// Exemplo:
+//
+//	info, err := m.DownloadFile("/exports/1", "/tmp/", WithDownloadProgress(bar.Update, 0))
+func WithDownloadProgress(fn ProgressFunc, interval time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.download = &progressConfig{fn: fn, interval: interval}
+	}
+}
+
+// SetUploadProgress reporta o progresso do envio do corpo da requisição.
+//
+// Parâmetros:
+//   - fn: Função que recebe os relatórios
+//   - interval: Intervalo mínimo entre relatórios (zero usa 100ms)
+//
+// Exemplo:
+//
+//	r.SetBody(file).SetUploadProgress(bar.Update, time.Second)
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetUploadProgress(fn ProgressFunc, interval time.Duration) *request {
+	r.upload = &progressConfig{fn: fn, interval: interval}
+	return r
+}
+
+// SetDownloadProgress reporta o progresso da leitura do corpo da resposta de Send.
+// O relatório final é entregue quando o corpo é lido até o fim ou fechado.
+//
+// Parâmetros:
+//   - fn: Função que recebe os relatórios
+//   - interval: Intervalo mínimo entre relatórios (zero usa 100ms)
+//
+// Exemplo:
+//
+//	resp, err := r.SetDownloadProgress(bar.Update, 0).Send()
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetDownloadProgress(fn ProgressFunc, interval time.Duration) *request {
+	r.download = &progressConfig{fn: fn, interval: interval}
+	return r
+}
+
+// progressTracker acompanha uma transferência e entrega os relatórios a uma
+// goroutine dedicada, que chama a ProgressFunc sequencialmente.
+type progressTracker struct {
+	fn       ProgressFunc
+	interval time.Duration
+
+	mu         sync.Mutex
+	total      int64
+	bytes      int64
+	base       int64
+	start      time.Time
+	lastReport time.Time
+	lastBytes  int64
+	finished   bool
+
+	updates chan Progress
+	done    chan struct{}
+}
+
+// newProgressTracker cria um tracker e inicia a goroutine de entrega.
+// Retorna nil quando cfg é nil, o que desativa o acompanhamento.
+func newProgressTracker(cfg *progressConfig) *progressTracker {
+	if cfg == nil || cfg.fn == nil {
+		return nil
+	}
+	t := &progressTracker{
+		fn:       cfg.fn,
+		interval: cfg.interval,
+		total:    -1,
+		updates:  make(chan Progress, 1),
+		done:     make(chan struct{}),
+	}
+	if t.interval <= 0 {
+		t.interval = defaultProgressInterval
+	}
+
+	go func() {
+		defer close(t.done)
+		for p := range t.updates {
+			t.fn(p)
+		}
+	}()
+	return t
+}
+
+// reset reinicia a contagem a partir de offset bytes, com o total informado
+// (-1 quando desconhecido). Os bytes de offset não entram na taxa média.
+func (t *progressTracker) reset(offset, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.bytes, t.base, t.lastBytes = offset, offset, offset
+	t.total = total
+	t.start, t.lastReport = now, time.Time{}
+}
+
+// wrap retorna um leitor que contabiliza os bytes lidos de r.
+func (t *progressTracker) wrap(r io.ReadCloser) io.ReadCloser {
+	return &progressReader{ReadCloser: r, tracker: t}
+}
+
+// add contabiliza n bytes e emite um relatório se o intervalo tiver passado.
+func (t *progressTracker) add(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.bytes += int64(n)
+	now := time.Now()
+	if !t.lastReport.IsZero() && now.Sub(t.lastReport) < t.interval {
+		return
+	}
+	t.emit(now, false)
+}
+
+// finish emite o relatório final e aguarda a sua entrega.
+// Pode ser chamada mais de uma vez; apenas a primeira tem efeito.
+func (t *progressTracker) finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.finished {
+		t.mu.Unlock()
+		return
+	}
+	t.finished = true
+	t.emit(time.Now(), true)
+	close(t.updates)
+	t.mu.Unlock()
+
+	<-t.done
+}
+
+// emit calcula o relatório e o coloca na fila, substituindo um relatório ainda
+// não entregue. Deve ser chamada com mu travado.
+func (t *progressTracker) emit(now time.Time, done bool) {
+	if t.finished && !done {
+		return
+	}
+	if t.start.IsZero() {
+		t.start = now
+	}
+
+	p := Progress{Bytes: t.bytes, Total: t.total, ETA: -1, Elapsed: now.Sub(t.start), Done: done}
+	if elapsed := now.Sub(t.start).Seconds(); elapsed > 0 {
+		p.AverageRate = float64(t.bytes-t.base) / elapsed
+	}
+	since := t.start
+	if !t.lastReport.IsZero() {
+		since = t.lastReport
+	}
+	if elapsed := now.Sub(since).Seconds(); elapsed > 0 {
+		p.Rate = float64(t.bytes-t.lastBytes) / elapsed
+	}
+	switch {
+	case done:
+		p.ETA = 0
+	case t.total >= 0 && p.AverageRate > 0:
+		p.ETA = time.Duration(float64(t.total-t.bytes) / p.AverageRate * float64(time.Second))
+	}
+	t.lastReport, t.lastBytes = now, t.bytes
+
+	select {
+	case t.updates <- p:
+	default:
+		// Drop the pending report in favor of the newest one
+		select {
+		case <-t.updates:
+		default:
+		}
+		t.updates <- p
+	}
+}
+
+// progressReader contabiliza os bytes lidos de um corpo.
+type progressReader struct {
+	io.ReadCloser
+	tracker *progressTracker
+
+	// finishOnEOF entrega o relatório final ao fim da leitura ou no Close (usado em Send).
+	finishOnEOF bool
+}
+
+// Read lê do corpo e contabiliza os bytes lidos.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n > 0 {
+		p.tracker.add(n)
+	}
+	if err == io.EOF && p.finishOnEOF {
+		p.tracker.finish()
+	}
+	return n, err
+}
+
+// Close fecha o corpo e, quando configurado, entrega o relatório final.
+func (p *progressReader) Close() error {
+	err := p.ReadCloser.Close()
+	if p.finishOnEOF {
+		p.tracker.finish()
+	}
+	return err
+}
+
+// uploadTrackerKey é a chave do tracker de envio no contexto da requisição.
+type uploadTrackerKey struct{}
+
+// withUploadTracker associa o tracker de envio à requisição.
+// O corpo é contabilizado apenas no momento do envio, depois da assinatura.
+func withUploadTracker(req *http.Request, t *progressTracker) *http.Request {
+	if t == nil {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), uploadTrackerKey{}, t))
+}
+
+// trackUpload contabiliza o corpo de req quando existir um tracker de envio.
+// A contagem recomeça a cada envio.
+func trackUpload(req *http.Request) {
+	t, ok := req.Context().Value(uploadTrackerKey{}).(*progressTracker)
+	if !ok || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	t.reset(0, total)
+	req.Body = t.wrap(req.Body)
+}
+
+// trackDownload contabiliza o corpo de resp a partir de offset bytes.
+// Retorna o corpo original quando t é nil.
+func trackDownload(t *progressTracker, body io.ReadCloser, offset, total int64) io.ReadCloser {
+	if t == nil {
+		return body
+	}
+	t.reset(offset, total)
+	return t.wrap(body)
+}
+
+// contentLength retorna o tamanho do corpo da resposta, ou -1 quando desconhecido.
+func contentLength(resp *http.Response) int64 {
+	if resp.ContentLength < 0 {
+		return -1
+	}
+	return resp.ContentLength
+}
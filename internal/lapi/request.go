@@ -1,8 +1,13 @@
 package lapi
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -38,9 +43,53 @@ type request struct {
 	// Pode ser qualquer implementação de io.Reader.
 	body io.Reader
 
+	// Source gera o corpo da requisição a cada envio (ex: multipart).
+	// Quando definido, substitui Body.
+	source *bodySource
+
+	// ContentType é o Content-Type do corpo definido pelos helpers de body.
+	// Só é enviado quando a requisição tem corpo e o usuário não definiu o header.
+	contentType string
+
 	// Timeout é o tempo máximo de resposta da requisição HTTP.
 	// Se não especificado, será usado o timeout padrão do cliente HTTP.
 	timeout time.Duration
+
+	// Auth é o mecanismo de autenticação aplicado a cada envio da requisição.
+	// Exemplo: NewBasicAuth("usuario", "senha"), NewDigestAuth("usuario", "senha")
+	auth Authenticator
+
+	// Signer assina a requisição depois da autenticação, a cada envio.
+	// Exemplo: NewHMACSigner([]byte("segredo"))
+	signer Signer
+
+	// Transport é o transporte HTTP usado para enviar a requisição.
+	// Se não especificado, será usado o transporte padrão do Go.
+	transport http.RoundTripper
+
+	// Compressor comprime o corpo da requisição antes do envio.
+	// Exemplo: NewGzipCompressor(gzip.DefaultCompression)
+	compressor Compressor
+
+	// CompressThreshold é o tamanho mínimo, em bytes, do corpo a ser comprimido.
+	compressThreshold int64
+
+	// MaxRatio é a razão máxima entre o tamanho descomprimido e o comprimido das respostas.
+	// Zero usa o padrão (100); um valor negativo desativa o limite.
+	maxRatio int64
+
+	// MaxBodySize é o tamanho máximo, em bytes, do corpo das respostas.
+	// Zero não limita o tamanho.
+	maxBodySize int64
+
+	// Upload e Download reportam o progresso do envio e do recebimento dos corpos.
+	upload   *progressConfig
+	download *progressConfig
+
+	// Errs são os erros de construção da requisição, por setter (ex: "baseURL",
+	// "header:X-Id"). Quando existirem, o envio falha antes de qualquer I/O de rede.
+	// Um setter chamado novamente com sucesso remove o seu erro.
+	errs map[string]error
 }
 
 // SetBaseURL define a URL base para a requisição HTTP.
@@ -52,6 +101,7 @@ type request struct {
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
 func (r *request) SetBaseURL(baseURL string) *request {
+	r.setErr("baseURL", validateBaseURL(baseURL))
 	r.baseURL = baseURL
 	return r
 }
@@ -64,7 +114,121 @@ func (r *request) SetBaseURL(baseURL string) *request {
 //	r.SetMethod("POST")
 //
 // Retorna a própria requisição para permitir encadeamento de métodos.
+// Se o método for inválido, o erro é registrado e retornado por Err.
 func (r *request) SetMethod(method string) *request {
+	var err error
+	if !validToken(method) {
+		err = fmt.Errorf("lapi: método HTTP inválido: %q", method)
+	}
+	r.setErr("method", err)
 	r.method = method
 	return r
 }
+
+// SetAuthenticator define o mecanismo de autenticação da requisição.
+//
+// Exemplo:
+//
+//	r.SetAuthenticator(NewBasicAuth("usuario", "senha"))
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetAuthenticator(auth Authenticator) *request {
+	r.auth = auth
+	return r
+}
+
+// SetSigner define o mecanismo de assinatura da requisição.
+// A assinatura é calculada depois que headers e body estão definidos e
+// recalculada a cada reenvio.
+//
+// Exemplo:
+//
+//	r.SetSigner(NewHMACSigner([]byte("segredo")))
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+func (r *request) SetSigner(signer Signer) *request {
+	r.signer = signer
+	return r
+}
+
+// Err retorna os erros acumulados durante a construção da requisição,
+// como falhas na conversão para JSON, headers inválidos ou URL malformada.
+// Send e os verbos do modelo falham com este erro antes de qualquer I/O de rede.
+//
+// Exemplo:
+//
+//	r.SetBodyJSON(payload)
+//	if err := r.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Retorna nil se não houver erros.
+func (r *request) Err() error {
+	keys := make([]string, 0, len(r.errs))
+	for key := range r.errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		errs[i] = r.errs[key]
+	}
+	return errors.Join(errs...)
+}
+
+// setErr registra o erro de construção do setter key, ou o remove quando err é nil.
+func (r *request) setErr(key string, err error) {
+	if err == nil {
+		delete(r.errs, key)
+		return
+	}
+	if r.errs == nil {
+		r.errs = make(map[string]error)
+	}
+	r.errs[key] = err
+}
+
+// validateBaseURL verifica se a URL base é absoluta e usa http ou https.
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("lapi: URL base inválida: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("lapi: URL base inválida: %q (o esquema deve ser http ou https)", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("lapi: URL base inválida: %q (host não informado)", baseURL)
+	}
+	return nil
+}
+
+// validateHeader verifica o nome e o valor de um header HTTP.
+func validateHeader(key, value string) error {
+	if !validToken(key) {
+		return fmt.Errorf("lapi: nome de header inválido: %q", key)
+	}
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return fmt.Errorf("lapi: valor inválido para o header %q", key)
+	}
+	return nil
+}
+
+// validToken verifica se s é um token HTTP válido (RFC 9110), usado em métodos
+// e nomes de headers.
+func validToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,314 @@
+package lapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithResume torna o DownloadFile retomável. O conteúdo é gravado em um arquivo
+// parcial (".<nome>.part") que é mantido em caso de falha; a próxima chamada continua
+// de onde parou com "Range: bytes=N-" e If-Range (ETag ou Last-Modified). Se o servidor
+// ignorar o Range ou o arquivo tiver mudado, o download é refeito desde o início.
+//
+// Parâmetros:
+//   - retries: Quantidade de retomadas automáticas em falhas transitórias
+//     (conexão interrompida, 408, 429 e 5xx)
+//
+// Exemplo:
+//
+//	info, err := m.DownloadFile("/exports/2024.csv", "/data/export.csv", WithResume(5))
+func WithResume(retries int) CallOption {
+	return func(o *callOptions) {
+		o.resume = true
+		o.retries = retries
+	}
+}
+
+// resumeDownload executa o download retomável, repetindo a tentativa em falhas transitórias.
+func (m *model) resumeDownload(path, target string, options *callOptions) (*DownloadInfo, *httpError) {
+	partial := resumePath(target, path)
+	download := newProgressTracker(options.download)
+	defer download.finish()
+
+	var done <-chan struct{}
+	if options.ctx != nil {
+		done = options.ctx.Done()
+	}
+
+	var lastErr *httpError
+	for attempt := 0; attempt <= options.retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(resumeBackoff(attempt))
+			select {
+			case <-done:
+				timer.Stop()
+				return nil, lastErr
+			case <-timer.C:
+			}
+		}
+		info, retry, httpErr := m.resumeAttempt(path, target, partial, options, download)
+		if httpErr == nil {
+			return info, nil
+		}
+		if !retry {
+			return nil, httpErr
+		}
+		lastErr = httpErr
+	}
+	return nil, lastErr
+}
+
+// resumeAttempt faz uma tentativa do download retomável. Retorna se o erro,
+// quando houver, é transitório e a tentativa pode ser repetida.
+func (m *model) resumeAttempt(path, target, partial string, options *callOptions, download *progressTracker) (*DownloadInfo, bool, *httpError) {
+	offset, validator := loadResumeState(partial)
+
+	attempt := *options
+	attempt.headers = make(map[string]string, len(options.headers)+3)
+	for key, value := range options.headers {
+		attempt.headers[key] = value
+	}
+	if headerValue(attempt.headers, "Accept-Encoding") == "" {
+		attempt.headers["Accept-Encoding"] = "identity"
+	}
+	// Without a validator the partial file cannot be safely continued
+	if offset > 0 && validator != "" {
+		attempt.headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+		attempt.headers["If-Range"] = validator
+	} else {
+		offset = 0
+	}
+
+	resp, httpErr := m.exchange(http.MethodGet, path, nil, &attempt)
+	if httpErr != nil {
+		return nil, networkError(httpErr), httpErr
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	digestHeaders := []string{"Content-Digest", "Repr-Digest"}
+	total := contentLength(resp)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			removeResumeState(partial)
+			if err == nil {
+				err = fmt.Errorf("lapi: Content-Range começa em %d, esperado %d", start, offset)
+			}
+			return nil, true, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 11")
+		}
+		flags |= os.O_APPEND
+		total = size
+		// Content-Digest covers only the range
+		digestHeaders = []string{"Repr-Digest"}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file may already be complete
+		_, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || total != offset {
+			removeResumeState(partial)
+			return nil, true, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+		}
+		return m.finishResume(resp, target, partial, options, []string{"Repr-Digest"})
+	case resp.StatusCode >= 400:
+		return nil, transientStatus(resp.StatusCode), m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	default:
+		flags |= os.O_TRUNC
+		offset = 0
+	}
+
+	if err := saveResumeState(partial, resp.Header); err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+
+	limit := options.bodyLimit(m.request.maxBodySize)
+	if limit > 0 && offset+resp.ContentLength > limit {
+		removeResumeState(partial)
+		return nil, false, m.wrapError(http.StatusInternalServerError, &BodyTooLargeError{Limit: limit}, "Houve um erro interno no servidor! C: 04")
+	}
+	if limit > 0 {
+		// A remaining limit of 0 would mean unlimited for limitBody
+		if limit-offset <= 0 {
+			removeResumeState(partial)
+			return nil, false, m.wrapError(http.StatusInternalServerError, &BodyTooLargeError{Limit: limit}, "Houve um erro interno no servidor! C: 04")
+		}
+		limit -= offset
+	}
+
+	file, err := os.OpenFile(partial, flags, 0o644)
+	if err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	body := &readErrRecorder{r: limitBody(trackDownload(download, resp.Body, offset, total), limit)}
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		var tooLarge *BodyTooLargeError
+		switch {
+		case errors.As(body.err, &tooLarge):
+			removeResumeState(partial)
+			return nil, false, m.wrapError(http.StatusInternalServerError, body.err, "Houve um erro interno no servidor! C: 04")
+		case body.err != nil:
+			return nil, true, m.wrapError(http.StatusInternalServerError, body.err, "Houve um erro interno no servidor! C: 04")
+		}
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+
+	return m.finishResume(resp, target, partial, options, digestHeaders)
+}
+
+// finishResume verifica os checksums do arquivo parcial completo e o move para o destino.
+// Se os checksums não conferirem, o arquivo parcial é descartado.
+func (m *model) finishResume(resp *http.Response, target, partial string, options *callOptions, digestHeaders []string) (*DownloadInfo, bool, *httpError) {
+	info := newDownloadInfo(resp)
+	info.Path = downloadPath(target, info.Filename)
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		info.ContentType = ""
+	}
+
+	hasher, err := newDownloadHasher(options, resp.Header, digestHeaders...)
+	if err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 10")
+	}
+	file, err := os.Open(partial)
+	if err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	info.Size, err = io.Copy(hasher, file)
+	file.Close()
+	if err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	info.SHA256 = hasher.sha256Hex()
+
+	if err := hasher.verify(); err != nil {
+		removeResumeState(partial)
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 10")
+	}
+	if err := os.Rename(partial, info.Path); err != nil {
+		return nil, false, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	os.Remove(partial + ".meta")
+	return info, false, nil
+}
+
+// resumePath retorna o caminho do arquivo parcial. Quando target é um diretório,
+// o nome final só é conhecido na resposta, então é usado o último segmento da URL.
+func resumePath(target, urlPath string) string {
+	urlPath, _, _ = strings.Cut(urlPath, "?")
+	name := safeFilename(path.Base(urlPath))
+	if name == "" {
+		name = "download"
+	}
+	file := downloadPath(target, name)
+	return filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".part")
+}
+
+// loadResumeState retorna o tamanho do arquivo parcial e o validador (ETag ou
+// Last-Modified) da resposta que o originou.
+func loadResumeState(partial string) (int64, string) {
+	stat, err := os.Stat(partial)
+	if err != nil {
+		return 0, ""
+	}
+	validator, err := os.ReadFile(partial + ".meta")
+	if err != nil {
+		return stat.Size(), ""
+	}
+	return stat.Size(), string(validator)
+}
+
+// saveResumeState grava o validador da resposta ao lado do arquivo parcial.
+// ETags fracas não são usadas, pois If-Range exige comparação forte.
+func saveResumeState(partial string, header http.Header) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+	if validator == "" {
+		os.Remove(partial + ".meta")
+		return nil
+	}
+	return writeFileAtomic(partial+".meta", []byte(validator), 0o600)
+}
+
+// removeResumeState descarta o arquivo parcial e o seu validador.
+func removeResumeState(partial string) {
+	os.Remove(partial)
+	os.Remove(partial + ".meta")
+}
+
+// parseContentRange interpreta o header Content-Range ("bytes 100-999/1000",
+// "bytes 100-999/*" ou "bytes */1000"). O total é -1 quando desconhecido.
+func parseContentRange(value string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(value), "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("lapi: Content-Range inválido: %q", value)
+	}
+	rng, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("lapi: Content-Range inválido: %q", value)
+	}
+
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("lapi: Content-Range inválido: %q", value)
+		}
+	}
+	if rng == "*" {
+		return 0, total, nil
+	}
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("lapi: Content-Range inválido: %q", value)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("lapi: Content-Range inválido: %q", value)
+	}
+	return start, total, nil
+}
+
+// transientStatus indica se o status HTTP representa uma falha transitória.
+func transientStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// networkError indica se err é uma falha de rede no envio da requisição (conexão
+// recusada ou interrompida, timeout), que pode ser repetida. Erros de construção da
+// requisição, de codificação, de autenticação e o cancelamento do contexto não são.
+func networkError(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(urlErr.Err, &netErr) || errors.Is(urlErr.Err, io.EOF) || errors.Is(urlErr.Err, io.ErrUnexpectedEOF)
+}
+
+// resumeBackoff retorna o intervalo antes da retomada: 250ms, 500ms, 1s... até 10s.
+func resumeBackoff(attempt int) time.Duration {
+	delay := 250 * time.Millisecond << (attempt - 1)
+	if delay > 10*time.Second || delay <= 0 {
+		delay = 10 * time.Second
+	}
+	return delay
+}
@@ -0,0 +1,287 @@
+package lapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer é uma interface que representa um mecanismo de assinatura de requisições.
+// Sign é chamado depois da autenticação, quando headers e body já são definitivos,
+// e novamente a cada reenvio da requisição.
+//
+// Exemplo de uso:
+//
+//	m.SetSigner(NewHMACSigner([]byte("segredo")))
+type Signer interface {
+	// Sign assina a requisição HTTP, normalmente adicionando headers.
+	Sign(req *http.Request) error
+}
+
+// SignComponent representa um componente da string canônica assinada.
+type SignComponent int
+
+const (
+	// SignMethod é o método HTTP em letras maiúsculas.
+	SignMethod SignComponent = iota
+
+	// SignPath é o caminho da URL (ex: /v1/payments).
+	SignPath
+
+	// SignQuery é a query string com chaves e valores ordenados.
+	SignQuery
+
+	// SignBodyHash é o SHA-256 hexadecimal do corpo da requisição.
+	SignBodyHash
+
+	// SignTimestamp é o timestamp Unix (em segundos) da assinatura.
+	SignTimestamp
+
+	// SignNonce é um valor aleatório único por assinatura.
+	SignNonce
+)
+
+// SignatureEncoding representa a codificação da assinatura.
+type SignatureEncoding int
+
+const (
+	// SignatureHex codifica a assinatura em hexadecimal.
+	SignatureHex SignatureEncoding = iota
+
+	// SignatureBase64 codifica a assinatura em base64 padrão.
+	SignatureBase64
+)
+
+// hmacSigner é uma implementação de Signer que assina requisições com HMAC-SHA256
+// sobre uma string canônica formada pelos componentes configurados, separados por "\n".
+type hmacSigner struct {
+	key        []byte
+	components []SignComponent
+	encoding   SignatureEncoding
+
+	signatureHeader string
+	timestampHeader string
+	nonceHeader     string
+
+	keyIDHeader string
+	keyID       string
+
+	now   func() time.Time
+	nonce func() (string, error)
+}
+
+// NewHMACSigner cria um Signer HMAC-SHA256.
+// Por padrão a string canônica contém método, caminho, query ordenada, hash do body,
+// timestamp e nonce, e a assinatura é enviada em hexadecimal nos headers
+// X-Signature, X-Timestamp e X-Nonce.
+//
+// Parâmetros:
+//   - key: Segredo compartilhado com o parceiro
+//
+// Exemplo:
+//
+//	signer := NewHMACSigner([]byte("segredo")).
+//	    WithEncoding(SignatureBase64).
+//	    WithKeyID("X-Key-Id", "parceiro-123")
+//	m.SetSigner(signer)
+func NewHMACSigner(key []byte) *hmacSigner {
+	return &hmacSigner{
+		key:             key,
+		components:      []SignComponent{SignMethod, SignPath, SignQuery, SignBodyHash, SignTimestamp, SignNonce},
+		encoding:        SignatureHex,
+		signatureHeader: "X-Signature",
+		timestampHeader: "X-Timestamp",
+		nonceHeader:     "X-Nonce",
+		now:             time.Now,
+		nonce:           func() (string, error) { return randomHex(16) },
+	}
+}
+
+// WithComponents define quais componentes formam a string canônica, e em que ordem.
+//
+// Exemplo:
+//
+//	signer.WithComponents(SignMethod, SignPath, SignTimestamp)
+//
+// Retorna o próprio Signer para permitir encadeamento de métodos.
+func (s *hmacSigner) WithComponents(components ...SignComponent) *hmacSigner {
+	s.components = components
+	return s
+}
+
+// WithHeaders define os nomes dos headers da assinatura, do timestamp e do nonce.
+// Um nome vazio faz com que o header correspondente não seja enviado.
+//
+// Exemplo:
+//
+//	signer.WithHeaders("X-Partner-Signature", "X-Partner-Timestamp", "X-Partner-Nonce")
+//
+// Retorna o próprio Signer para permitir encadeamento de métodos.
+func (s *hmacSigner) WithHeaders(signature, timestamp, nonce string) *hmacSigner {
+	s.signatureHeader = signature
+	s.timestampHeader = timestamp
+	s.nonceHeader = nonce
+	return s
+}
+
+// WithKeyID define um header com o identificador da chave usada na assinatura.
+//
+// Exemplo:
+//
+//	signer.WithKeyID("X-Key-Id", "parceiro-123")
+//
+// Retorna o próprio Signer para permitir encadeamento de métodos.
+func (s *hmacSigner) WithKeyID(header, keyID string) *hmacSigner {
+	s.keyIDHeader = header
+	s.keyID = keyID
+	return s
+}
+
+// WithEncoding define a codificação da assinatura (SignatureHex ou SignatureBase64).
+//
+// Retorna o próprio Signer para permitir encadeamento de métodos.
+func (s *hmacSigner) WithEncoding(encoding SignatureEncoding) *hmacSigner {
+	s.encoding = encoding
+	return s
+}
+
+// WithClock define a função usada para obter o horário da assinatura.
+// Útil em testes ou para compensar a diferença de relógio com o servidor.
+//
+// Exemplo:
+//
+//	signer.WithClock(func() time.Time { return time.Unix(1700000000, 0) })
+//
+// Retorna o próprio Signer para permitir encadeamento de métodos.
+func (s *hmacSigner) WithClock(now func() time.Time) *hmacSigner {
+	s.now = now
+	return s
+}
+
+// Sign calcula a assinatura HMAC-SHA256 da requisição e adiciona os headers configurados.
+func (s *hmacSigner) Sign(req *http.Request) error {
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	nonce, err := s.nonce()
+	if err != nil {
+		return err
+	}
+
+	parts := make([]string, 0, len(s.components))
+	for _, component := range s.components {
+		switch component {
+		case SignMethod:
+			parts = append(parts, strings.ToUpper(req.Method))
+		case SignPath:
+			parts = append(parts, req.URL.EscapedPath())
+		case SignQuery:
+			parts = append(parts, canonicalQuery(req.URL.Query()))
+		case SignBodyHash:
+			sum, err := bodySHA256(req)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, hex.EncodeToString(sum))
+		case SignTimestamp:
+			parts = append(parts, timestamp)
+		case SignNonce:
+			parts = append(parts, nonce)
+		}
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	signature := mac.Sum(nil)
+
+	var encoded string
+	if s.encoding == SignatureBase64 {
+		encoded = base64.StdEncoding.EncodeToString(signature)
+	} else {
+		encoded = hex.EncodeToString(signature)
+	}
+
+	if s.signatureHeader != "" {
+		req.Header.Set(s.signatureHeader, encoded)
+	}
+	if s.timestampHeader != "" {
+		req.Header.Set(s.timestampHeader, timestamp)
+	}
+	if s.nonceHeader != "" {
+		req.Header.Set(s.nonceHeader, nonce)
+	}
+	if s.keyIDHeader != "" {
+		req.Header.Set(s.keyIDHeader, s.keyID)
+	}
+	return nil
+}
+
+// canonicalQuery codifica a query com chaves e valores ordenados.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(query))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// bodySHA256 calcula o SHA-256 do corpo da requisição sem consumi-lo.
+// Quando o corpo não pode ser recriado, ele é lido para a memória e substituído
+// por uma cópia reenviável.
+func bodySHA256(req *http.Request) ([]byte, error) {
+	h := sha256.New()
+	if req.Body == nil || req.Body == http.NoBody {
+		return h.Sum(nil), nil
+	}
+
+	if req.GetBody == nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		h.Write(data)
+		return h.Sum(nil), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	if _, err := io.Copy(h, body); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// randomHex gera size bytes aleatórios codificados em hexadecimal.
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
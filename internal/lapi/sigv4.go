@@ -0,0 +1,463 @@
+package lapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PayloadMode indica como o corpo da requisição entra na assinatura SigV4.
+type PayloadMode int
+
+const (
+	// PayloadSigned assina o SHA-256 do corpo completo.
+	PayloadSigned PayloadMode = iota
+
+	// PayloadUnsigned envia UNSIGNED-PAYLOAD no lugar do hash do corpo.
+	// Útil para uploads grandes para o S3 sobre HTTPS.
+	PayloadUnsigned
+
+	// PayloadStreaming assina o corpo em blocos (STREAMING-AWS4-HMAC-SHA256-PAYLOAD),
+	// sem precisar ler o corpo inteiro antes do envio. Exige Content-Length conhecido.
+	PayloadStreaming
+)
+
+const (
+	sigV4Algorithm      = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat     = "20060102T150405Z"
+	sigV4DateFormat     = "20060102"
+	sigV4UnsignedBody   = "UNSIGNED-PAYLOAD"
+	sigV4StreamingBody  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4EmptySHA256    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sigV4ChunkSize      = 64 * 1024
+	sigV4ChunkSignature = ";chunk-signature="
+	sigV4MaxExpires     = 7 * 24 * time.Hour
+)
+
+// sigV4Ignored são os headers que nunca entram na assinatura, pois podem
+// ser alterados por proxies ou pelo transporte HTTP.
+var sigV4Ignored = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"content-length":  true,
+}
+
+// sigV4Signer é uma implementação de Authenticator que assina requisições com
+// AWS Signature Version 4. Funciona com a AWS, o API Gateway e serviços compatíveis
+// com S3 (ex: MinIO).
+type sigV4Signer struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	region       string
+	service      string
+	payload      PayloadMode
+	now          func() time.Time
+}
+
+// NewSigV4Signer cria um Authenticator AWS Signature Version 4.
+//
+// Parâmetros:
+//   - accessKey: Access key ID
+//   - secretKey: Secret access key
+//   - region: Região (ex: "us-east-1")
+//   - service: Nome do serviço (ex: "s3", "execute-api")
+//
+// Exemplo:
+//
+//	signer := NewSigV4Signer("AKID...", "segredo", "us-east-1", "s3").
+//	    WithPayloadMode(PayloadUnsigned)
+//	m.SetAuthenticator(signer)
+func NewSigV4Signer(accessKey, secretKey, region, service string) *sigV4Signer {
+	return &sigV4Signer{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    region,
+		service:   service,
+		payload:   PayloadSigned,
+		now:       time.Now,
+	}
+}
+
+// WithSessionToken define o token de sessão de credenciais temporárias (STS).
+//
+// Retorna o próprio Authenticator para permitir encadeamento de métodos.
+func (s *sigV4Signer) WithSessionToken(token string) *sigV4Signer {
+	s.sessionToken = token
+	return s
+}
+
+// WithPayloadMode define como o corpo entra na assinatura
+// (PayloadSigned, PayloadUnsigned ou PayloadStreaming).
+//
+// Retorna o próprio Authenticator para permitir encadeamento de métodos.
+func (s *sigV4Signer) WithPayloadMode(mode PayloadMode) *sigV4Signer {
+	s.payload = mode
+	return s
+}
+
+// WithClock define a função usada para obter o horário da assinatura.
+//
+// Retorna o próprio Authenticator para permitir encadeamento de métodos.
+func (s *sigV4Signer) WithClock(now func() time.Time) *sigV4Signer {
+	s.now = now
+	return s
+}
+
+// Authenticate assina a requisição e adiciona o header Authorization.
+func (s *sigV4Signer) Authenticate(req *http.Request) error {
+	t := s.now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	var payloadHash string
+	switch s.payload {
+	case PayloadUnsigned:
+		payloadHash = sigV4UnsignedBody
+	case PayloadStreaming:
+		if err := s.prepareStreaming(req); err != nil {
+			return err
+		}
+		payloadHash = sigV4StreamingBody
+	default:
+		sum, err := bodySHA256(req)
+		if err != nil {
+			return err
+		}
+		payloadHash = hex.EncodeToString(sum)
+	}
+	if s.service == "s3" || s.payload != PayloadSigned {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := s.canonicalHeaders(req)
+	canonical := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	signature := s.signature(t, canonical)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKey, s.scope(t), signedHeaders, signature,
+	))
+
+	if s.payload == PayloadStreaming && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &sigV4ChunkReader{
+			signer:    s,
+			body:      req.Body,
+			time:      t,
+			previous:  signature,
+			chunkSize: sigV4ChunkSize,
+		}
+	}
+	return nil
+}
+
+// Presign gera uma URL pré-assinada válida pelo tempo informado.
+// A URL pode ser usada sem credenciais, por exemplo para download direto do S3.
+//
+// Parâmetros:
+//   - method: Método HTTP (ex: "GET", "PUT")
+//   - rawURL: URL completa do recurso
+//   - expires: Tempo de validade da URL (de 1 segundo a 7 dias)
+//
+// Exemplo:
+//
+//	link, err := signer.Presign("GET", "https://minio.local/bucket/arquivo.pdf", 15*time.Minute)
+func (s *sigV4Signer) Presign(method, rawURL string, expires time.Duration) (string, error) {
+	if expires < time.Second || expires > sigV4MaxExpires {
+		return "", fmt.Errorf("lapi: validade da URL pré-assinada deve estar entre 1s e 7 dias, recebido %s", expires)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	t := s.now().UTC()
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(t))
+	query.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		s.canonicalURI(u),
+		sigV4CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		sigV4UnsignedBody,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(t, canonical))
+	u.RawQuery = sigV4CanonicalQuery(query)
+	return u.String(), nil
+}
+
+// prepareStreaming ajusta os headers e o Content-Length para o envio em blocos assinados.
+func (s *sigV4Signer) prepareStreaming(req *http.Request) error {
+	decoded := req.ContentLength
+	if v := req.Header.Get("X-Amz-Decoded-Content-Length"); v != "" {
+		decoded, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if req.Body != nil && req.Body != http.NoBody && decoded <= 0 {
+		return errors.New("lapi: SigV4 streaming exige Content-Length conhecido")
+	}
+
+	// aws-chunked comes first and keeps other codings (ex: gzip) applied to the body
+	if encoding := req.Header.Get("Content-Encoding"); encoding == "" {
+		req.Header.Set("Content-Encoding", "aws-chunked")
+	} else if !strings.HasPrefix(encoding, "aws-chunked") {
+		req.Header.Set("Content-Encoding", "aws-chunked,"+encoding)
+	}
+	req.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(decoded, 10))
+	req.ContentLength = sigV4StreamingLength(decoded, sigV4ChunkSize)
+	return nil
+}
+
+// canonicalHeaders retorna a lista de headers assinados e o bloco de headers canônicos.
+func (s *sigV4Signer) canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers["host"] = host
+	if s.payload == PayloadStreaming && req.ContentLength > 0 {
+		headers["content-length"] = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	for key, values := range req.Header {
+		name := strings.ToLower(key)
+		if sigV4Ignored[name] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalURI retorna o caminho canônico da URL.
+// Para o S3 o caminho é codificado uma única vez e sem normalização;
+// para os demais serviços o caminho enviado na linha de requisição é
+// normalizado e codificado novamente (ex: "arn%3Aaws" vira "arn%253Aaws").
+func (s *sigV4Signer) canonicalURI(u *url.URL) string {
+	if s.service == "s3" {
+		if u.Path == "" {
+			return "/"
+		}
+		return sigV4Escape(u.Path, false)
+	}
+
+	wire := sigV4WirePath(u)
+	if wire == "" {
+		return "/"
+	}
+	clean := path.Clean(wire)
+	if strings.HasSuffix(wire, "/") && clean != "/" {
+		clean += "/"
+	}
+	return sigV4Escape(clean, false)
+}
+
+// scope retorna o escopo da credencial (data/região/serviço/aws4_request).
+func (s *sigV4Signer) scope(t time.Time) string {
+	return strings.Join([]string{t.Format(sigV4DateFormat), s.region, s.service, "aws4_request"}, "/")
+}
+
+// signature calcula a assinatura da requisição canônica.
+func (s *sigV4Signer) signature(t time.Time, canonical string) string {
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		t.Format(sigV4TimeFormat),
+		s.scope(t),
+		sigV4Hex([]byte(canonical)),
+	}, "\n")
+	return hex.EncodeToString(sigV4HMAC(s.signingKey(t), stringToSign))
+}
+
+// signingKey deriva a chave de assinatura do dia, região e serviço.
+func (s *sigV4Signer) signingKey(t time.Time) []byte {
+	key := sigV4HMAC([]byte("AWS4"+s.secretKey), t.Format(sigV4DateFormat))
+	key = sigV4HMAC(key, s.region)
+	key = sigV4HMAC(key, s.service)
+	return sigV4HMAC(key, "aws4_request")
+}
+
+// sigV4ChunkReader codifica o corpo em blocos aws-chunked, cada um assinado
+// com a assinatura do bloco anterior.
+type sigV4ChunkReader struct {
+	signer    *sigV4Signer
+	body      io.ReadCloser
+	time      time.Time
+	previous  string
+	chunkSize int
+
+	buf  bytes.Buffer
+	done bool
+}
+
+// Read implementa io.Reader.
+func (r *sigV4ChunkReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		chunk := make([]byte, r.chunkSize)
+		n, err := io.ReadFull(r.body, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		r.writeChunk(chunk[:n])
+		if n == 0 {
+			r.done = true
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// Close implementa io.Closer.
+func (r *sigV4ChunkReader) Close() error {
+	return r.body.Close()
+}
+
+// writeChunk assina e escreve um bloco no buffer.
+func (r *sigV4ChunkReader) writeChunk(data []byte) {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-PAYLOAD",
+		r.time.Format(sigV4TimeFormat),
+		r.signer.scope(r.time),
+		r.previous,
+		sigV4EmptySHA256,
+		sigV4Hex(data),
+	}, "\n")
+	r.previous = hex.EncodeToString(sigV4HMAC(r.signer.signingKey(r.time), stringToSign))
+
+	fmt.Fprintf(&r.buf, "%x%s%s\r\n", len(data), sigV4ChunkSignature, r.previous)
+	r.buf.Write(data)
+	r.buf.WriteString("\r\n")
+}
+
+// sigV4StreamingLength calcula o tamanho do corpo codificado em blocos aws-chunked.
+func sigV4StreamingLength(decoded int64, chunkSize int) int64 {
+	chunkLength := func(size int64) int64 {
+		return int64(len(strconv.FormatInt(size, 16))) + int64(len(sigV4ChunkSignature)) + 64 + 2 + size + 2
+	}
+
+	full := decoded / int64(chunkSize)
+	length := full * chunkLength(int64(chunkSize))
+	if rest := decoded % int64(chunkSize); rest > 0 {
+		length += chunkLength(rest)
+	}
+	return length + chunkLength(0)
+}
+
+// sigV4CanonicalQuery codifica a query ordenada pela chave codificada e, em seguida,
+// pelo valor, usando a codificação de URI exigida pela SigV4.
+func sigV4CanonicalQuery(query url.Values) string {
+	type pair struct{ key, value string }
+	pairs := make([]pair, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, pair{sigV4Escape(key, true), sigV4Escape(value, true)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+// sigV4WirePath retorna o caminho exatamente como é enviado na linha de requisição.
+// Respeita RawPath (via EscapedPath) e URLs com Opaque definido.
+func sigV4WirePath(u *url.URL) string {
+	if u.Opaque == "" {
+		return u.EscapedPath()
+	}
+	p := u.Opaque
+	if strings.HasPrefix(p, "//") {
+		// "//host/caminho": the path starts after the authority
+		if i := strings.Index(p[2:], "/"); i >= 0 {
+			return p[2+i:]
+		}
+		return "/"
+	}
+	return p
+}
+
+// sigV4Escape codifica s mantendo apenas os caracteres não reservados (A-Z, a-z, 0-9, -_.~).
+// Quando encodeSlash é false, "/" também é mantida.
+func sigV4Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// sigV4HMAC calcula o HMAC-SHA256 de data com a chave informada.
+func sigV4HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sigV4Hex calcula o SHA-256 hexadecimal de data.
+func sigV4Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,346 @@
+package lapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event é um evento recebido de um stream text/event-stream (Server-Sent Events).
+type Event struct {
+	// ID é o último id recebido no stream (campo "id").
+	ID string
+
+	// Event é o tipo do evento (campo "event"). Por padrão, "message".
+	Event string
+
+	// Data são as linhas do campo "data", unidas por "\n".
+	Data string
+
+	// Retry é o intervalo de reconexão informado pelo servidor (campo "retry"), ou zero.
+	Retry time.Duration
+}
+
+// defaultSSERetry é o intervalo de reconexão padrão, quando o servidor não informa "retry".
+const defaultSSERetry = 3 * time.Second
+
+// defaultMaxLineSize é o tamanho máximo padrão de uma linha dos streams (SSE, NDJSON).
+const defaultMaxLineSize = 1 << 20
+
+// eventSource é um cliente de Server-Sent Events que usa a configuração do modelo
+// (URL base, headers, autenticação, TLS). Reconecta automaticamente ao fim do
+// stream ou em falhas transitórias, enviando Last-Event-ID e respeitando o "retry" do servidor.
+//
+// Exemplo de uso:
+//
+//	es := m.EventSource("/notifications").WithLastEventID(lastID)
+//	for event, err := range es.Events(ctx) {
+//	    if err != nil {
+//	        log.Println(err)
+//	        break
+//	    }
+//	    fmt.Println(event.Event, event.Data)
+//	}
+type eventSource struct {
+	m    *model
+	path string
+	opts []CallOption
+
+	lastEventID   string
+	retry         time.Duration
+	maxReconnects int
+	maxLineSize   int
+}
+
+// EventSource cria um cliente de Server-Sent Events para path.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/events")
+//   - opts: Opções aplicadas a cada conexão (ex: WithHeader)
+//
+// Exemplo:
+//
+//	es := m.EventSource("/events", WithHeader("X-Tenant", "acme"))
+func (m *model) EventSource(path string, opts ...CallOption) *eventSource {
+	return &eventSource{
+		m:             m,
+		path:          path,
+		opts:          opts,
+		retry:         defaultSSERetry,
+		maxReconnects: -1,
+		maxLineSize:   defaultMaxLineSize,
+	}
+}
+
+// WithLastEventID define o id enviado em Last-Event-ID na primeira conexão,
+// para continuar um stream a partir do último evento processado.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (s *eventSource) WithLastEventID(id string) *eventSource {
+	s.lastEventID = id
+	return s
+}
+
+// WithRetry define o intervalo de reconexão usado até o servidor informar outro. Por padrão, 3s.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (s *eventSource) WithRetry(d time.Duration) *eventSource {
+	s.retry = d
+	return s
+}
+
+// WithMaxReconnects limita a quantidade de reconexões seguidas sem receber eventos.
+// Por padrão, -1 (sem limite).
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (s *eventSource) WithMaxReconnects(n int) *eventSource {
+	s.maxReconnects = n
+	return s
+}
+
+// WithMaxLineSize define o tamanho máximo, em bytes, de uma linha do stream. Por padrão, 1 MiB.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (s *eventSource) WithMaxLineSize(size int) *eventSource {
+	s.maxLineSize = size
+	return s
+}
+
+// LastEventID retorna o id do último evento recebido.
+func (s *eventSource) LastEventID() string {
+	return s.lastEventID
+}
+
+// Events retorna um iterador sobre os eventos do stream.
+// A iteração termina quando ctx é cancelado, quando o servidor responde 204, ou
+// com um erro (ex: status não transitório, Content-Type inválido, limite de
+// reconexões). Interromper o laço fecha a conexão.
+//
+// Exemplo:
+//
+//	for event, err := range es.Events(ctx) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    handle(event)
+//	}
+func (s *eventSource) Events(ctx context.Context) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		stopped := false
+		err := s.run(ctx, func(event Event) bool {
+			if !yield(event, nil) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil && !stopped {
+			yield(Event{}, err)
+		}
+	}
+}
+
+// Subscribe entrega os eventos do stream em um canal, lido por uma goroutine própria.
+// Os dois canais são fechados quando o stream termina; o canal de erros recebe
+// o erro final, se houver. Cancele ctx para encerrar a conexão.
+//
+// Exemplo:
+//
+//	events, errs := es.Subscribe(ctx)
+//	for event := range events {
+//	    handle(event)
+//	}
+//	if err := <-errs; err != nil {
+//	    log.Println(err)
+//	}
+func (s *eventSource) Subscribe(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(events)
+		err := s.run(ctx, func(event Event) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return events, errs
+}
+
+// run conecta ao stream e entrega os eventos a deliver, reconectando quando necessário.
+// Retorna nil quando ctx é cancelado, quando deliver retorna false ou quando o servidor responde 204.
+func (s *eventSource) run(ctx context.Context, deliver func(Event) bool) error {
+	failures := 0
+	for {
+		received, stop, err := s.connect(ctx, deliver)
+		if stop || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && !errors.Is(err, errSSEReconnect) {
+			return err
+		}
+
+		if received {
+			failures = 0
+		} else {
+			failures++
+		}
+		if s.maxReconnects >= 0 && failures > s.maxReconnects {
+			if err == nil {
+				err = errSSEReconnect
+			}
+			return fmt.Errorf("lapi: limite de reconexões do stream excedido: %w", err)
+		}
+
+		timer := time.NewTimer(s.retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// errSSEReconnect indica uma falha transitória da conexão, que leva a uma reconexão.
+var errSSEReconnect = errors.New("lapi: conexão do stream interrompida")
+
+// connect abre uma conexão e lê os eventos até o fim do stream.
+// Retorna se algum evento foi recebido, se a leitura deve parar e o erro da conexão.
+// Erros transitórios são retornados envolvendo errSSEReconnect.
+func (s *eventSource) connect(ctx context.Context, deliver func(Event) bool) (bool, bool, error) {
+	headers := []CallOption{
+		WithHeader("Accept", "text/event-stream"),
+		WithHeader("Cache-Control", "no-cache"),
+		WithHeader("Accept-Encoding", "identity"),
+	}
+	if s.lastEventID != "" {
+		headers = append(headers, WithHeader("Last-Event-ID", s.lastEventID))
+	}
+	options := newCallOptions(append(append(headers, s.opts...), WithContext(ctx)))
+	options.stream = true
+
+	resp, httpErr := s.m.exchange(http.MethodGet, s.path, nil, options)
+	if httpErr != nil {
+		// Invalid requests, codec and auth errors do not improve with a reconnect
+		if !networkError(httpErr) {
+			return false, false, httpErr
+		}
+		return false, false, fmt.Errorf("%w: %w", errSSEReconnect, httpErr)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, true, nil
+	case transientStatus(resp.StatusCode):
+		return false, false, fmt.Errorf("%w: %s", errSSEReconnect, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return false, false, s.m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return false, false, fmt.Errorf("lapi: Content-Type do stream inválido: %q", resp.Header.Get("Content-Type"))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, min(4096, s.maxLineSize)), s.maxLineSize)
+	scanner.Split(scanSSELines)
+
+	received := false
+	var event Event
+	var data bytes.Buffer
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		// An empty line dispatches the event
+		if line == "" {
+			if data.Len() > 0 {
+				event.ID = s.lastEventID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				received = true
+				if !deliver(event) {
+					return true, true, nil
+				}
+			}
+			event, data = Event{}, bytes.Buffer{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+				s.retry = event.Retry
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return received, false, fmt.Errorf("lapi: linha do stream excede %d bytes", s.maxLineSize)
+		}
+		return received, false, fmt.Errorf("%w: %w", errSSEReconnect, err)
+	}
+	return received, false, nil
+}
+
+// scanSSELines separa as linhas do stream, terminadas por CRLF, LF ou CR.
+func scanSSELines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the buffer may be followed by LF
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		// An incomplete event at the end of the stream is discarded
+		return len(data), nil, bufio.ErrFinalToken
+	}
+	return 0, nil, nil
+}
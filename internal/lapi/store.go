@@ -0,0 +1,199 @@
+package lapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Tokens representa o par de tokens JWT persistido por um TokenStore.
+type Tokens struct {
+	// Token representa o token de acesso JWT.
+	Token string `json:"token"`
+
+	// RefreshToken representa o token de atualização JWT.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenStore é uma interface que representa um armazenamento de tokens.
+// Permite que os tokens definidos com SetAuth sobrevivam ao reinício do processo.
+//
+// Exemplo de uso:
+//
+//	store, _ := NewFileTokenStore("/var/lib/app/tokens.json", "LAPI_TOKEN_KEY")
+//	m.SetTokenStore(store)
+type TokenStore interface {
+	// Load retorna os tokens armazenados.
+	// Quando não houver tokens armazenados, retorna Tokens vazio e nil.
+	Load() (Tokens, error)
+
+	// Save armazena os tokens, substituindo os anteriores.
+	Save(tokens Tokens) error
+}
+
+// memoryTokenStore é uma implementação de TokenStore que mantém os tokens em memória.
+// É útil em testes ou para compartilhar tokens entre vários modelos do mesmo processo.
+type memoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens Tokens
+}
+
+// NewMemoryTokenStore cria um novo TokenStore em memória.
+//
+// Exemplo:
+//
+//	store := NewMemoryTokenStore()
+//	m.SetTokenStore(store)
+func NewMemoryTokenStore() *memoryTokenStore {
+	return &memoryTokenStore{}
+}
+
+// Load retorna os tokens armazenados em memória.
+func (s *memoryTokenStore) Load() (Tokens, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tokens, nil
+}
+
+// Save armazena os tokens em memória.
+func (s *memoryTokenStore) Save(tokens Tokens) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = tokens
+	return nil
+}
+
+// fileTokenStore é uma implementação de TokenStore que persiste os tokens em um arquivo.
+// A escrita é atômica (arquivo temporário + rename) e o arquivo é criado com permissão 0600.
+// Quando uma chave é configurada, o conteúdo é criptografado com AES-GCM.
+type fileTokenStore struct {
+	mu   sync.Mutex
+	path string
+	aead cipher.AEAD
+}
+
+// NewFileTokenStore cria um novo TokenStore baseado em arquivo.
+//
+// Parâmetros:
+//   - path: Caminho do arquivo onde os tokens serão armazenados
+//   - keyEnv: Nome da variável de ambiente que contém a chave de criptografia (opcional)
+//
+// Quando keyEnv é informado e a variável está definida, o conteúdo do arquivo é
+// criptografado com AES-256-GCM usando o SHA-256 do valor da variável como chave.
+//
+// Exemplo:
+//
+//	store, err := NewFileTokenStore("/var/lib/app/tokens.json", "LAPI_TOKEN_KEY")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func NewFileTokenStore(path string, keyEnv string) (*fileTokenStore, error) {
+	s := &fileTokenStore{path: path}
+
+	if keyEnv == "" {
+		return s, nil
+	}
+	secret := os.Getenv(keyEnv)
+	if secret == "" {
+		return s, nil
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	s.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load lê os tokens do arquivo.
+// Se o arquivo não existir, retorna Tokens vazio e nil.
+func (s *fileTokenStore) Load() (Tokens, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tokens Tokens
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return tokens, err
+	}
+
+	if s.aead != nil {
+		size := s.aead.NonceSize()
+		if len(data) < size {
+			return tokens, fmt.Errorf("lapi: arquivo de tokens corrompido: %s", s.path)
+		}
+		data, err = s.aead.Open(nil, data[:size], data[size:], nil)
+		if err != nil {
+			return tokens, fmt.Errorf("lapi: não foi possível descriptografar os tokens: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return tokens, err
+	}
+	return tokens, nil
+}
+
+// Save grava os tokens no arquivo de forma atômica.
+func (s *fileTokenStore) Save(tokens Tokens) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		data = s.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	return writeFileAtomic(s.path, data, 0600)
+}
+
+// writeFileAtomic grava data em path usando um arquivo temporário no mesmo diretório
+// seguido de rename, de forma que o arquivo nunca fique parcialmente escrito.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
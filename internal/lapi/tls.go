@@ -0,0 +1,284 @@
+package lapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TLSConfig contém as configurações TLS do cliente HTTP.
+//
+// Exemplo de uso:
+//
+//	err := m.SetTLSConfig(TLSConfig{
+//	    CertFile: "/etc/app/client.crt",
+//	    KeyFile:  "/etc/app/client.key",
+//	    CAFile:   "/etc/app/ca.pem",
+//	    Pins: map[string][]string{
+//	        "api.parceiro.com": {"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+//	    },
+//	})
+type TLSConfig struct {
+	// CertFile e KeyFile são os caminhos do certificado e da chave do cliente (PEM) para mTLS.
+	// Os arquivos são recarregados automaticamente quando alterados no disco.
+	CertFile string
+	KeyFile  string
+
+	// CertPEM e KeyPEM são o certificado e a chave do cliente em memória (PEM).
+	// São ignorados quando CertFile e KeyFile estão definidos.
+	CertPEM []byte
+	KeyPEM  []byte
+
+	// CAFile é o caminho de um bundle PEM de autoridades certificadoras confiáveis.
+	// Quando definido (ou CAPEM/RootCAs), substitui as CAs do sistema.
+	CAFile string
+
+	// CAPEM é um bundle PEM de autoridades certificadoras confiáveis em memória.
+	CAPEM []byte
+
+	// RootCAs é um pool de autoridades certificadoras confiáveis já construído.
+	RootCAs *x509.CertPool
+
+	// Pins são os hashes SHA-256 da SPKI aceitos por host, em base64
+	// (com ou sem o prefixo "sha256/"). A conexão falha se nenhum certificado
+	// da cadeia corresponder a um dos hashes do host. Quando há pins, conexões
+	// com hosts sem pins configurados também são recusadas.
+	Pins map[string][]string
+
+	// MinVersion é a versão mínima do TLS (ex: tls.VersionTLS13).
+	// Por padrão, TLS 1.2.
+	MinVersion uint16
+
+	// InsecureSkipVerify desativa a verificação do certificado do servidor.
+	// Só é permitido em ambiente de desenvolvimento.
+	InsecureSkipVerify bool
+}
+
+// errInsecureTLS indica que InsecureSkipVerify foi usado fora do ambiente de desenvolvimento.
+var errInsecureTLS = errors.New("lapi: InsecureSkipVerify só é permitido em ambiente de desenvolvimento")
+
+// SetTLSConfig define as configurações TLS usadas pela requisição.
+// InsecureSkipVerify não é permitido em requisições fora do contexto.
+//
+// Exemplo:
+//
+//	r := OutOfContext().SetTLSConfig(TLSConfig{CAFile: "/etc/app/ca.pem"})
+//
+// Retorna a própria requisição para permitir encadeamento de métodos.
+// Se os certificados não puderem ser carregados, o erro é registrado e retornado por Err.
+func (r *request) SetTLSConfig(cfg TLSConfig) *request {
+	transport, err := newTLSTransport(cfg, false)
+	if err != nil {
+		r.setErr("tls", err)
+		return r
+	}
+	r.setErr("tls", nil)
+	r.transport = transport
+	return r
+}
+
+// SetTLSConfig define as configurações TLS usadas pelo modelo.
+// InsecureSkipVerify só é permitido quando o modelo está em ambiente de desenvolvimento.
+//
+// Exemplo:
+//
+//	err := m.SetTLSConfig(TLSConfig{
+//	    CertFile:   "/etc/app/client.crt",
+//	    KeyFile:    "/etc/app/client.key",
+//	    MinVersion: tls.VersionTLS13,
+//	})
+//
+// Retorna um erro se os certificados não puderem ser carregados.
+func (m *model) SetTLSConfig(cfg TLSConfig) error {
+	transport, err := newTLSTransport(cfg, m.inDevelopment)
+	if err != nil {
+		return err
+	}
+	m.request.transport = transport
+	return nil
+}
+
+// newTLSTransport cria um http.Transport com as configurações TLS informadas.
+func newTLSTransport(cfg TLSConfig, allowInsecure bool) (*http.Transport, error) {
+	if cfg.InsecureSkipVerify && !allowInsecure {
+		return nil, errInsecureTLS
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         cfg.MinVersion,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	// Parse the root CAs
+	if cfg.RootCAs != nil || cfg.CAFile != "" || len(cfg.CAPEM) > 0 {
+		pool := cfg.RootCAs
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CAFile != "" {
+			data, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("lapi: nenhum certificado válido em %s", cfg.CAFile)
+			}
+		}
+		if len(cfg.CAPEM) > 0 && !pool.AppendCertsFromPEM(cfg.CAPEM) {
+			return nil, errors.New("lapi: nenhum certificado válido em CAPEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Parse the client certificate
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		loader := &certLoader{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		if _, err := loader.certificate(); err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loader.certificate()
+		}
+	} else if len(cfg.CertPEM) > 0 {
+		cert, err := tls.X509KeyPair(cfg.CertPEM, cfg.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	// Parse the pins
+	if len(cfg.Pins) > 0 {
+		pins := make(map[string]map[string]bool, len(cfg.Pins))
+		for host, hashes := range cfg.Pins {
+			pins[strings.ToLower(host)] = make(map[string]bool, len(hashes))
+			for _, hash := range hashes {
+				pins[strings.ToLower(host)][strings.TrimPrefix(hash, "sha256/")] = true
+			}
+		}
+
+		// Connections through a proxy (CONNECT) are handshaken by the transport,
+		// so the host comes from the SNI, which is empty for IP addresses
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyConnectionPins(pins, cs.ServerName, cs)
+		}
+
+		// Direct connections are handshaken here, so the pins are keyed by the dialed host
+		dial := transport.DialContext
+		transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			// The transport adds the HTTP/2 NextProtos to its config on first use
+			config := transport.TLSClientConfig.Clone()
+			if config.ServerName == "" {
+				config.ServerName = host
+			}
+			config.VerifyConnection = func(cs tls.ConnectionState) error {
+				return verifyConnectionPins(pins, host, cs)
+			}
+
+			tlsConn := tls.Client(conn, config)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return tlsConn, nil
+		}
+	}
+
+	return transport, nil
+}
+
+// verifyConnectionPins verifica os pins do host da conexão. A conexão é recusada
+// quando o host não pode ser identificado ou não possui pins configurados.
+func verifyConnectionPins(pins map[string]map[string]bool, host string, cs tls.ConnectionState) error {
+	if host == "" {
+		return errors.New("lapi: não foi possível identificar o host para verificar os pins")
+	}
+	host = strings.ToLower(host)
+	hashes, ok := pins[host]
+	if !ok {
+		return fmt.Errorf("lapi: nenhum pin configurado para %s", host)
+	}
+	return verifyPins(host, hashes, cs)
+}
+
+// verifyPins verifica se algum certificado da conexão corresponde aos hashes SPKI do host.
+func verifyPins(host string, hashes map[string]bool, cs tls.ConnectionState) error {
+	for _, cert := range cs.PeerCertificates {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		if hashes[base64.StdEncoding.EncodeToString(sum[:])] {
+			return nil
+		}
+	}
+	return fmt.Errorf("lapi: nenhum certificado de %s corresponde aos pins configurados", host)
+}
+
+// certLoader carrega o certificado do cliente do disco e o recarrega
+// sempre que o certificado ou a chave forem alterados (ex: rotação).
+type certLoader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// certificate retorna o certificado atual, recarregando-o se os arquivos mudaram.
+func (l *certLoader) certificate() (*tls.Certificate, error) {
+	certInfo, err := os.Stat(l.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := os.Stat(l.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	modTime := certInfo.ModTime()
+	if keyInfo.ModTime().After(modTime) {
+		modTime = keyInfo.ModTime()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cert != nil && modTime.Equal(l.modTime) {
+		return l.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		// Keep using the previous certificate while the rotation is in progress
+		if l.cert != nil {
+			return l.cert, nil
+		}
+		return nil, err
+	}
+	l.cert = &cert
+	l.modTime = modTime
+	return l.cert, nil
+}
@@ -0,0 +1,112 @@
+package lapi
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// tlsTestPin retorna o pin SHA-256 da SPKI do certificado do servidor de teste.
+func tlsTestPin(srv *httptest.Server) string {
+	sum := sha256.Sum256(srv.Certificate().RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newTLSTestProxy cria um proxy HTTP que encaminha todo CONNECT para o servidor informado.
+func newTLSTestProxy(t *testing.T, target *httptest.Server) *httptest.Server {
+	t.Helper()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", target.Listener.Addr().String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+
+		go io.Copy(upstream, rw)
+		io.Copy(conn, upstream)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func TestTLSPins(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	// rejected handshakes are expected, keep them out of the test output
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	proxy := newTLSTestProxy(t, srv)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	wrong := "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+
+	tests := []struct {
+		name    string
+		url     string
+		proxy   bool
+		pins    map[string][]string
+		wantErr string
+	}{
+		{name: "ip com pin", url: srv.URL, pins: map[string][]string{"127.0.0.1": {tlsTestPin(srv)}}},
+		{name: "ip com pin incorreto", url: srv.URL, pins: map[string][]string{"127.0.0.1": {wrong}}, wantErr: "corresponde aos pins"},
+		{name: "ip sem pin", url: srv.URL, pins: map[string][]string{"example.com": {tlsTestPin(srv)}}, wantErr: "nenhum pin configurado para 127.0.0.1"},
+		{name: "proxy com pin", url: "https://example.com:" + port, proxy: true, pins: map[string][]string{"example.com": {tlsTestPin(srv)}}},
+		{name: "proxy com pin incorreto", url: "https://example.com:" + port, proxy: true, pins: map[string][]string{"example.com": {wrong}}, wantErr: "corresponde aos pins"},
+		{name: "proxy sem pin", url: "https://example.com:" + port, proxy: true, pins: map[string][]string{"api.parceiro.com": {tlsTestPin(srv)}}, wantErr: "nenhum pin configurado para example.com"},
+		{name: "proxy com ip", url: "https://127.0.0.1:" + port, proxy: true, pins: map[string][]string{"127.0.0.1": {tlsTestPin(srv)}}, wantErr: "identificar o host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newTLSTransport(TLSConfig{RootCAs: roots, Pins: tt.pins}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer transport.CloseIdleConnections()
+			if tt.proxy {
+				proxyURL, _ := url.Parse(proxy.URL)
+				transport.Proxy = http.ProxyURL(proxyURL)
+			}
+
+			resp, err := (&http.Client{Transport: transport}).Get(tt.url)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				resp.Body.Close()
+				return
+			}
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("esperado erro de pin, conexão aceita")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperado conter %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,669 @@
+package lapi
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tusVersion é a versão do protocolo tus enviada em Tus-Resumable.
+const tusVersion = "1.0.0"
+
+// defaultTusChunkSize é o tamanho padrão de cada PATCH.
+const defaultTusChunkSize = 4 << 20
+
+// UploadStore guarda as URLs dos uploads tus em andamento, identificadas por uma
+// impressão digital do conteúdo, para que possam ser retomados após o reinício do processo.
+//
+// Exemplo de uso:
+//
+//	store, _ := NewFileUploadStore("/var/lib/app/uploads.json")
+//	tus := m.Tus("/files/").WithStore(store)
+type UploadStore interface {
+	// Get retorna a URL do upload, ou vazio quando não houver.
+	Get(fingerprint string) (string, error)
+
+	// Set armazena a URL do upload.
+	Set(fingerprint, uploadURL string) error
+
+	// Delete remove a URL do upload.
+	Delete(fingerprint string) error
+}
+
+// memoryUploadStore é uma implementação de UploadStore em memória.
+type memoryUploadStore struct {
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+// NewMemoryUploadStore cria um novo UploadStore em memória.
+//
+// Exemplo:
+//
+//	tus := m.Tus("/files/").WithStore(NewMemoryUploadStore())
+func NewMemoryUploadStore() *memoryUploadStore {
+	return &memoryUploadStore{urls: make(map[string]string)}
+}
+
+// Get retorna a URL do upload armazenada em memória.
+func (s *memoryUploadStore) Get(fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.urls[fingerprint], nil
+}
+
+// Set armazena a URL do upload em memória.
+func (s *memoryUploadStore) Set(fingerprint, uploadURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urls[fingerprint] = uploadURL
+	return nil
+}
+
+// Delete remove a URL do upload da memória.
+func (s *memoryUploadStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.urls, fingerprint)
+	return nil
+}
+
+// fileUploadStore é uma implementação de UploadStore que persiste as URLs em um
+// arquivo JSON, com escrita atômica e permissão 0600.
+type fileUploadStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileUploadStore cria um novo UploadStore baseado em arquivo.
+//
+// Parâmetros:
+//   - path: Caminho do arquivo onde as URLs serão armazenadas
+//
+// Exemplo:
+//
+//	store, err := NewFileUploadStore("/var/lib/app/uploads.json")
+func NewFileUploadStore(path string) (*fileUploadStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &fileUploadStore{path: path}, nil
+}
+
+// Get retorna a URL do upload armazenada no arquivo.
+func (s *fileUploadStore) Get(fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	return urls[fingerprint], err
+}
+
+// Set armazena a URL do upload no arquivo.
+func (s *fileUploadStore) Set(fingerprint, uploadURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	urls[fingerprint] = uploadURL
+	return s.save(urls)
+}
+
+// Delete remove a URL do upload do arquivo.
+func (s *fileUploadStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := urls[fingerprint]; !ok {
+		return nil
+	}
+	delete(urls, fingerprint)
+	return s.save(urls)
+}
+
+// load lê as URLs do arquivo. Se o arquivo não existir, retorna um mapa vazio.
+func (s *fileUploadStore) load() (map[string]string, error) {
+	urls := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return urls, nil
+	}
+	if err != nil {
+		return urls, err
+	}
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return urls, err
+	}
+	return urls, nil
+}
+
+// save grava as URLs no arquivo.
+func (s *fileUploadStore) save(urls map[string]string) error {
+	data, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0o600)
+}
+
+// TusUpload descreve o conteúdo de um upload tus.
+type TusUpload struct {
+	// Reader é a origem do conteúdo, lida por partes.
+	Reader io.ReaderAt
+
+	// Size é o tamanho total do conteúdo, em bytes.
+	Size int64
+
+	// Fingerprint identifica o conteúdo no UploadStore. Vazio desativa a retomada
+	// após o reinício do processo.
+	Fingerprint string
+
+	// Metadata é enviada em Upload-Metadata (ex: "filename", "filetype").
+	Metadata map[string]string
+}
+
+// tusClient envia arquivos para um servidor tus 1.0 (uploads retomáveis), com a
+// configuração do modelo (URL base, headers, autenticação, TLS).
+//
+// Exemplo de uso:
+//
+//	store, _ := NewFileUploadStore("/var/lib/app/uploads.json")
+//	uploadURL, err := m.Tus("/files/").
+//	    WithStore(store).
+//	    WithChunkSize(8 << 20).
+//	    WithChecksum().
+//	    UploadFile(ctx, "/videos/aula.mp4", map[string]string{"filetype": "video/mp4"})
+type tusClient struct {
+	m    *model
+	path string
+
+	store              UploadStore
+	chunkSize          int64
+	checksum           bool
+	creationWithUpload bool
+	retries            int
+}
+
+// Tus cria um cliente tus cujo endpoint de criação é path.
+//
+// Parâmetros:
+//   - path: Caminho do endpoint de criação (ex: "/files/")
+//
+// Exemplo:
+//
+//	tus := m.Tus("/files/")
+func (m *model) Tus(path string) *tusClient {
+	return &tusClient{m: m, path: path, chunkSize: defaultTusChunkSize, retries: 3}
+}
+
+// WithStore define onde as URLs dos uploads são guardadas para a retomada.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *tusClient) WithStore(store UploadStore) *tusClient {
+	c.store = store
+	return c
+}
+
+// WithChunkSize define o tamanho máximo de cada PATCH. Por padrão, 4 MiB;
+// zero envia o restante do conteúdo em uma única requisição.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *tusClient) WithChunkSize(size int64) *tusClient {
+	c.chunkSize = size
+	return c
+}
+
+// WithChecksum envia o SHA-1 de cada parte em Upload-Checksum (extensão checksum).
+// Partes recusadas pelo servidor (460) são reenviadas.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *tusClient) WithChecksum() *tusClient {
+	c.checksum = true
+	return c
+}
+
+// WithCreationWithUpload envia a primeira parte junto com a criação do upload
+// (extensão creation-with-upload), economizando uma requisição.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *tusClient) WithCreationWithUpload() *tusClient {
+	c.creationWithUpload = true
+	return c
+}
+
+// WithRetries define quantas falhas seguidas (rede, status transitório ou offset
+// divergente) são toleradas; após cada uma, o offset é consultado com HEAD e o envio
+// continua de onde parou. Por padrão, 3.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (c *tusClient) WithRetries(n int) *tusClient {
+	c.retries = n
+	return c
+}
+
+// UploadFile envia o arquivo em path. A impressão digital usada na retomada é
+// formada pelo caminho absoluto, tamanho e data de modificação do arquivo, e
+// "filename" é incluído em Metadata quando ausente.
+//
+// Parâmetros:
+//   - ctx: Contexto do upload
+//   - path: Caminho do arquivo
+//   - metadata: Metadados enviados em Upload-Metadata, ou nil
+//   - opts: Opções das requisições (ex: WithHeader, WithUploadProgress)
+//
+// Retorna a URL do upload, inclusive quando o envio falha depois da criação, e o erro.
+func (c *tusClient) UploadFile(ctx context.Context, path string, metadata map[string]string, opts ...CallOption) (string, *httpError) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", c.m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", c.m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	meta := make(map[string]string, len(metadata)+1)
+	for key, value := range metadata {
+		meta[key] = value
+	}
+	if _, ok := meta["filename"]; !ok {
+		meta["filename"] = filepath.Base(path)
+	}
+
+	return c.Upload(ctx, TusUpload{
+		Reader:      file,
+		Size:        stat.Size(),
+		Fingerprint: fmt.Sprintf("%s:%d:%d", abs, stat.Size(), stat.ModTime().UnixNano()),
+		Metadata:    meta,
+	}, opts...)
+}
+
+// Upload envia o conteúdo de upload. Se o UploadStore tiver uma URL para a
+// impressão digital, o offset é consultado com HEAD e o envio continua de onde
+// parou; caso contrário, um novo upload é criado. Ao terminar, a URL é removida do UploadStore.
+//
+// Parâmetros:
+//   - ctx: Contexto do upload
+//   - upload: Conteúdo, tamanho, impressão digital e metadados
+//   - opts: Opções das requisições (ex: WithHeader, WithUploadProgress)
+//
+// Retorna a URL do upload, inclusive quando o envio falha depois da criação, e o erro.
+func (c *tusClient) Upload(ctx context.Context, upload TusUpload, opts ...CallOption) (string, *httpError) {
+	m := c.m
+	if httpErr := c.checkRequest(); httpErr != nil {
+		return "", httpErr
+	}
+	options := newCallOptions(opts)
+	progress := newProgressTracker(options.upload)
+	defer progress.finish()
+
+	uploadURL, offset, httpErr := c.resume(ctx, upload, options)
+	if httpErr != nil {
+		return "", httpErr
+	}
+	if uploadURL == nil {
+		if uploadURL, offset, httpErr = c.create(ctx, upload, options, progress); httpErr != nil {
+			return "", httpErr
+		}
+	}
+
+	failures := 0
+	for offset < upload.Size {
+		next, retry, httpErr := c.patch(ctx, uploadURL, upload, offset, options, progress)
+		if httpErr == nil {
+			offset, failures = next, 0
+			continue
+		}
+		if !retry || ctx.Err() != nil || failures >= c.retries {
+			return uploadURL.String(), httpErr
+		}
+		failures++
+
+		timer := time.NewTimer(resumeBackoff(failures))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return uploadURL.String(), httpErr
+		case <-timer.C:
+		}
+		// Ask the server how much it has stored before sending again
+		if offset, _, httpErr = c.head(ctx, uploadURL, options); httpErr != nil {
+			return uploadURL.String(), httpErr
+		}
+	}
+
+	if c.store != nil && upload.Fingerprint != "" {
+		if err := c.store.Delete(upload.Fingerprint); err != nil {
+			return uploadURL.String(), m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+		}
+	}
+	return uploadURL.String(), nil
+}
+
+// Offset consulta com HEAD quantos bytes do upload o servidor já recebeu.
+//
+// Retorna o offset e o tamanho total (-1 quando adiado), ou um erro.
+func (c *tusClient) Offset(ctx context.Context, uploadURL string, opts ...CallOption) (int64, int64, *httpError) {
+	if httpErr := c.checkRequest(); httpErr != nil {
+		return 0, 0, httpErr
+	}
+	target, err := c.resolve(uploadURL)
+	if err != nil {
+		return 0, 0, c.m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+	}
+	return c.head(ctx, target, newCallOptions(opts))
+}
+
+// Terminate cancela o upload no servidor (extensão termination) e remove a sua
+// URL do UploadStore, quando fingerprint é informado.
+//
+// Parâmetros:
+//   - ctx: Contexto da requisição
+//   - uploadURL: URL do upload
+//   - fingerprint: Impressão digital do upload no UploadStore, ou vazio
+//
+// Retorna um erro se o servidor recusar o cancelamento.
+func (c *tusClient) Terminate(ctx context.Context, uploadURL, fingerprint string, opts ...CallOption) *httpError {
+	m := c.m
+	if httpErr := c.checkRequest(); httpErr != nil {
+		return httpErr
+	}
+	target, err := c.resolve(uploadURL)
+	if err != nil {
+		return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+	}
+
+	resp, httpErr := c.send(ctx, http.MethodDelete, target, nil, nil, nil, newCallOptions(opts))
+	if httpErr != nil {
+		return httpErr
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusGone {
+		return m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+
+	if c.store != nil && fingerprint != "" {
+		if err := c.store.Delete(fingerprint); err != nil {
+			return m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+		}
+	}
+	return nil
+}
+
+// checkRequest retorna os erros registrados na configuração da requisição,
+// como em exchange, já que as chamadas tus são enviadas por send.
+func (c *tusClient) checkRequest() *httpError {
+	if err := c.m.request.Err(); err != nil {
+		return c.m.wrapError(http.StatusInternalServerError, err, "Requisição inválida: "+err.Error())
+	}
+	return nil
+}
+
+// resume procura a URL do upload no UploadStore e consulta o seu offset.
+// Retorna URL nil quando o upload precisa ser criado.
+func (c *tusClient) resume(ctx context.Context, upload TusUpload, options *callOptions) (*url.URL, int64, *httpError) {
+	m := c.m
+	if c.store == nil || upload.Fingerprint == "" {
+		return nil, 0, nil
+	}
+	stored, err := c.store.Get(upload.Fingerprint)
+	if err != nil {
+		return nil, 0, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	if stored == "" {
+		return nil, 0, nil
+	}
+	target, err := c.resolve(stored)
+	if err != nil {
+		if err := c.store.Delete(upload.Fingerprint); err != nil {
+			return nil, 0, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+		}
+		return nil, 0, nil
+	}
+
+	offset, length, httpErr := c.head(ctx, target, options)
+	switch {
+	case httpErr == nil && (length < 0 || length == upload.Size) && offset <= upload.Size:
+		return target, offset, nil
+	case httpErr == nil, httpErr.statusCode == http.StatusNotFound, httpErr.statusCode == http.StatusGone, httpErr.statusCode == http.StatusForbidden:
+		// The upload expired or does not match the content: start again
+		if err := c.store.Delete(upload.Fingerprint); err != nil {
+			return nil, 0, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+		}
+		return nil, 0, nil
+	}
+	return nil, 0, httpErr
+}
+
+// create cria o upload (POST) e, com creation-with-upload, envia a primeira parte.
+// Retorna a URL do upload e o offset confirmado pelo servidor.
+func (c *tusClient) create(ctx context.Context, upload TusUpload, options *callOptions, progress *progressTracker) (*url.URL, int64, *httpError) {
+	m := c.m
+	base, err := url.Parse(m.request.baseURL + c.path)
+	if err != nil {
+		return nil, 0, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 02")
+	}
+
+	headers := map[string]string{"Upload-Length": strconv.FormatInt(upload.Size, 10)}
+	if len(upload.Metadata) > 0 {
+		headers["Upload-Metadata"] = encodeTusMetadata(upload.Metadata)
+	}
+
+	var body *io.SectionReader
+	if c.creationWithUpload && upload.Size > 0 {
+		body = io.NewSectionReader(upload.Reader, 0, c.chunk(upload.Size))
+		if httpErr := c.chunkHeaders(headers, body); httpErr != nil {
+			return nil, 0, httpErr
+		}
+		if progress != nil {
+			progress.reset(0, upload.Size)
+		}
+	}
+
+	resp, httpErr := c.send(ctx, http.MethodPost, base, headers, body, progress, options)
+	if httpErr != nil {
+		return nil, 0, httpErr
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, 0, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusCreated || location == "" {
+		return nil, 0, m.wrapError(http.StatusInternalServerError, fmt.Errorf("lapi: criação do upload tus sem Location (%s)", resp.Status), "Houve um erro interno no servidor! C: 12")
+	}
+	target, err := sameOrigin(resp.Request.URL, location)
+	if err != nil {
+		return nil, 0, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 12")
+	}
+
+	if c.store != nil && upload.Fingerprint != "" {
+		if err := c.store.Set(upload.Fingerprint, target.String()); err != nil {
+			return nil, 0, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+		}
+	}
+
+	offset := int64(0)
+	if body != nil {
+		// The server may store only part of the first chunk
+		if value := resp.Header.Get("Upload-Offset"); value != "" {
+			offset, err = strconv.ParseInt(value, 10, 64)
+			if err != nil || offset < 0 || offset > upload.Size {
+				return nil, 0, m.wrapError(http.StatusInternalServerError, fmt.Errorf("lapi: Upload-Offset inválido: %q", value), "Houve um erro interno no servidor! C: 12")
+			}
+		} else if offset, _, httpErr = c.head(ctx, target, options); httpErr != nil {
+			return nil, 0, httpErr
+		}
+	}
+	return target, offset, nil
+}
+
+// patch envia a parte que começa em offset. Retorna o novo offset e se a falha
+// permite uma nova tentativa após consultar o offset.
+func (c *tusClient) patch(ctx context.Context, target *url.URL, upload TusUpload, offset int64, options *callOptions, progress *progressTracker) (int64, bool, *httpError) {
+	m := c.m
+	body := io.NewSectionReader(upload.Reader, offset, c.chunk(upload.Size-offset))
+	headers := map[string]string{"Upload-Offset": strconv.FormatInt(offset, 10)}
+	if httpErr := c.chunkHeaders(headers, body); httpErr != nil {
+		return 0, false, httpErr
+	}
+	if progress != nil {
+		progress.reset(offset, upload.Size)
+	}
+
+	resp, httpErr := c.send(ctx, http.MethodPatch, target, headers, body, progress, options)
+	if httpErr != nil {
+		return 0, true, httpErr
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict, resp.StatusCode == 460, transientStatus(resp.StatusCode):
+		// Offset mismatch, checksum mismatch or temporary failure
+		return 0, true, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	case resp.StatusCode >= 400:
+		return 0, false, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+
+	next, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || next <= offset || next > upload.Size {
+		return 0, false, m.wrapError(http.StatusInternalServerError, fmt.Errorf("lapi: Upload-Offset inválido: %q", resp.Header.Get("Upload-Offset")), "Houve um erro interno no servidor! C: 12")
+	}
+	return next, false, nil
+}
+
+// head consulta o offset do upload. Retorna o offset e o tamanho total (-1 quando adiado).
+func (c *tusClient) head(ctx context.Context, target *url.URL, options *callOptions) (int64, int64, *httpError) {
+	m := c.m
+	resp, httpErr := c.send(ctx, http.MethodHead, target, map[string]string{"Cache-Control": "no-store"}, nil, nil, options)
+	if httpErr != nil {
+		return 0, 0, httpErr
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, 0, m.MakeError(resp.StatusCode, resp.Status, "Status code >= 400")
+	}
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, 0, m.wrapError(http.StatusInternalServerError, fmt.Errorf("lapi: Upload-Offset inválido: %q", resp.Header.Get("Upload-Offset")), "Houve um erro interno no servidor! C: 12")
+	}
+	length := int64(-1)
+	if value := resp.Header.Get("Upload-Length"); value != "" {
+		if length, err = strconv.ParseInt(value, 10, 64); err != nil {
+			length = -1
+		}
+	}
+	return offset, length, nil
+}
+
+// chunk retorna o tamanho da próxima parte, dado o que falta enviar.
+func (c *tusClient) chunk(remaining int64) int64 {
+	if c.chunkSize <= 0 {
+		return remaining
+	}
+	return min(c.chunkSize, remaining)
+}
+
+// chunkHeaders adiciona os headers do corpo de uma parte, com o checksum quando ativo.
+func (c *tusClient) chunkHeaders(headers map[string]string, body *io.SectionReader) *httpError {
+	headers["Content-Type"] = "application/offset+octet-stream"
+	if !c.checksum {
+		return nil
+	}
+	hash := sha1.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(body, 0, body.Size())); err != nil {
+		return c.m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 09")
+	}
+	headers["Upload-Checksum"] = "sha1 " + base64.StdEncoding.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// send envia uma requisição tus com os headers e a autenticação do modelo.
+// A parte enviada em body é contabilizada em progress, quando informado.
+func (c *tusClient) send(ctx context.Context, method string, target *url.URL, headers map[string]string, body *io.SectionReader, progress *progressTracker, options *callOptions) (*http.Response, *httpError) {
+	m := c.m
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, m.MakeError(http.StatusInternalServerError, err.Error(), "Houve um erro interno no servidor! C: 02")
+	}
+	if body != nil && body.Size() > 0 {
+		req.GetBody = func() (io.ReadCloser, error) {
+			section := io.NopCloser(io.NewSectionReader(body, 0, body.Size()))
+			if progress == nil {
+				return section, nil
+			}
+			return progress.wrap(section), nil
+		}
+		req.Body, _ = req.GetBody()
+		req.ContentLength = body.Size()
+	}
+
+	setHeaders(req, m.request.headers, headers["Content-Type"])
+	req.Header.Set("Tus-Resumable", tusVersion)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	for key, value := range options.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := m.send(req)
+	if err != nil {
+		return nil, m.wrapError(http.StatusInternalServerError, err, "Houve um erro interno no servidor! C: 03")
+	}
+	return resp, nil
+}
+
+// resolve interpreta uma URL de upload, relativa à URL base do modelo.
+func (c *tusClient) resolve(uploadURL string) (*url.URL, error) {
+	base, err := url.Parse(c.m.request.baseURL + c.path)
+	if err != nil {
+		return nil, err
+	}
+	return sameOrigin(base, uploadURL)
+}
+
+// encodeTusMetadata codifica os metadados no formato de Upload-Metadata
+// (chave e valor em base64, separados por vírgula), em ordem alfabética.
+func encodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,407 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tusTestUpload é um upload armazenado pelo servidor de teste.
+type tusTestUpload struct {
+	length   int64
+	metadata map[string]string
+	data     []byte
+}
+
+// tusTestServer é um servidor tus 1.0 mínimo, com as extensões creation,
+// creation-with-upload, checksum (sha1) e termination.
+type tusTestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	uploads  map[string]*tusTestUpload
+	next     int
+	requests []string
+	patches  int
+
+	// patchStatus, quando definido, recebe o número do PATCH (a partir de 1) e
+	// retorna um status de falha para a requisição, ou zero para processá-la.
+	patchStatus func(n int) int
+}
+
+func newTusTestServer(t *testing.T) *tusTestServer {
+	t.Helper()
+	s := &tusTestServer{uploads: make(map[string]*tusTestUpload)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tusTestServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	w.Header().Set("Tus-Resumable", "1.0.0")
+	if r.Header.Get("Tus-Resumable") != "1.0.0" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/files/" {
+		length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.next++
+		id := strconv.Itoa(s.next)
+		upload := &tusTestUpload{length: length, metadata: decodeTusTestMetadata(r.Header.Get("Upload-Metadata"))}
+		s.uploads[id] = upload
+
+		if r.ContentLength > 0 {
+			if status := s.append(upload, r); status != 0 {
+				w.WriteHeader(status)
+				return
+			}
+			w.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		}
+		w.Header().Set("Location", "/files/"+id)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	upload, ok := s.uploads[strings.TrimPrefix(r.URL.Path, "/files/")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.length, 10))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		s.patches++
+		if s.patchStatus != nil {
+			if status := s.patchStatus(s.patches); status != 0 {
+				w.WriteHeader(status)
+				return
+			}
+		}
+		if r.Header.Get("Upload-Offset") != strconv.Itoa(len(upload.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if status := s.append(upload, r); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(s.uploads, strings.TrimPrefix(r.URL.Path, "/files/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// append valida o corpo de uma parte e o acrescenta ao upload.
+// Retorna o status de erro, ou zero quando a parte é aceita.
+func (s *tusTestServer) append(upload *tusTestUpload, r *http.Request) int {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		return http.StatusUnsupportedMediaType
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	if checksum := r.Header.Get("Upload-Checksum"); checksum != "" {
+		sum := sha1.Sum(data)
+		if checksum != "sha1 "+base64.StdEncoding.EncodeToString(sum[:]) {
+			return 460
+		}
+	}
+	if int64(len(upload.data)+len(data)) > upload.length {
+		return http.StatusRequestEntityTooLarge
+	}
+	upload.data = append(upload.data, data...)
+	return 0
+}
+
+// calls retorna as requisições recebidas desde a posição from.
+func (s *tusTestServer) calls(from int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests[from:]...)
+}
+
+func (s *tusTestServer) upload(id string) *tusTestUpload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploads[id]
+}
+
+func decodeTusTestMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, _ := base64.StdEncoding.DecodeString(value)
+		metadata[key] = string(decoded)
+	}
+	return metadata
+}
+
+// tusTestData retorna um conteúdo de n bytes.
+func tusTestData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte('a' + i%26)
+	}
+	return data
+}
+
+func TestTusUpload(t *testing.T) {
+	tests := []struct {
+		name               string
+		creationWithUpload bool
+		want               []string
+	}{
+		{"creation", false, []string{"POST /files/", "PATCH /files/1", "PATCH /files/1", "PATCH /files/1"}},
+		{"creation-with-upload", true, []string{"POST /files/", "PATCH /files/1", "PATCH /files/1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTusTestServer(t)
+			store := NewMemoryUploadStore()
+			tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithStore(store).WithChunkSize(10)
+			if tt.creationWithUpload {
+				tus.WithCreationWithUpload()
+			}
+
+			data := tusTestData(25)
+			uploadURL, err := tus.Upload(context.Background(), TusUpload{
+				Reader:      bytes.NewReader(data),
+				Size:        int64(len(data)),
+				Fingerprint: "arquivo",
+				Metadata:    map[string]string{"filename": "relatório.pdf", "filetype": "application/pdf"},
+			})
+			if err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+
+			if uploadURL != srv.URL+"/files/1" {
+				t.Errorf("Upload = %q, want %q", uploadURL, srv.URL+"/files/1")
+			}
+			upload := srv.upload("1")
+			if !bytes.Equal(upload.data, data) {
+				t.Errorf("conteúdo recebido = %q, want %q", upload.data, data)
+			}
+			if upload.metadata["filename"] != "relatório.pdf" || upload.metadata["filetype"] != "application/pdf" {
+				t.Errorf("metadata = %v", upload.metadata)
+			}
+			if got := srv.calls(0); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("requisições = %v, want %v", got, tt.want)
+			}
+			if stored, _ := store.Get("arquivo"); stored != "" {
+				t.Errorf("UploadStore mantém %q após o término", stored)
+			}
+		})
+	}
+}
+
+func TestTusResume(t *testing.T) {
+	srv := newTusTestServer(t)
+	store := NewMemoryUploadStore()
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithStore(store).WithChunkSize(10)
+
+	data := tusTestData(25)
+	upload := TusUpload{Reader: bytes.NewReader(data), Size: int64(len(data)), Fingerprint: "arquivo"}
+
+	// The second chunk is refused with a non-retryable status
+	srv.patchStatus = func(n int) int {
+		if n == 2 {
+			return http.StatusForbidden
+		}
+		return 0
+	}
+	uploadURL, err := tus.Upload(context.Background(), upload)
+	if err == nil || err.StatusCode() != http.StatusForbidden {
+		t.Fatalf("Upload = %v, want erro 403", err)
+	}
+	if stored, _ := store.Get("arquivo"); stored != uploadURL {
+		t.Fatalf("UploadStore = %q, want %q", stored, uploadURL)
+	}
+
+	offset, length, err := tus.Offset(context.Background(), uploadURL)
+	if err != nil || offset != 10 || length != 25 {
+		t.Fatalf("Offset = %d, %d, %v, want 10, 25, nil", offset, length, err)
+	}
+
+	srv.patchStatus = nil
+	from := len(srv.calls(0))
+	resumed, err := tus.Upload(context.Background(), upload)
+	if err != nil {
+		t.Fatalf("Upload retomado: %v", err)
+	}
+	if resumed != uploadURL {
+		t.Errorf("Upload retomado = %q, want %q", resumed, uploadURL)
+	}
+	want := []string{"HEAD /files/1", "PATCH /files/1", "PATCH /files/1"}
+	if got := srv.calls(from); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requisições = %v, want %v", got, want)
+	}
+	if got := srv.upload("1").data; !bytes.Equal(got, data) {
+		t.Errorf("conteúdo recebido = %q, want %q", got, data)
+	}
+}
+
+func TestTusResumeExpired(t *testing.T) {
+	srv := newTusTestServer(t)
+	store := NewMemoryUploadStore()
+	store.Set("arquivo", srv.URL+"/files/expirado")
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithStore(store)
+
+	data := tusTestData(5)
+	if _, err := tus.Upload(context.Background(), TusUpload{Reader: bytes.NewReader(data), Size: 5, Fingerprint: "arquivo"}); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	want := []string{"HEAD /files/expirado", "POST /files/", "PATCH /files/1"}
+	if got := srv.calls(0); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requisições = %v, want %v", got, want)
+	}
+}
+
+func TestTusChecksumRetry(t *testing.T) {
+	srv := newTusTestServer(t)
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithChunkSize(10).WithChecksum()
+
+	// The first attempt of the second chunk fails the checksum
+	srv.patchStatus = func(n int) int {
+		if n == 2 {
+			return 460
+		}
+		return 0
+	}
+
+	data := tusTestData(25)
+	if _, err := tus.Upload(context.Background(), TusUpload{Reader: bytes.NewReader(data), Size: int64(len(data))}); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	want := []string{"POST /files/", "PATCH /files/1", "PATCH /files/1", "HEAD /files/1", "PATCH /files/1", "PATCH /files/1"}
+	if got := srv.calls(0); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requisições = %v, want %v", got, want)
+	}
+	if got := srv.upload("1").data; !bytes.Equal(got, data) {
+		t.Errorf("conteúdo recebido = %q, want %q", got, data)
+	}
+}
+
+func TestTusRetriesExhausted(t *testing.T) {
+	srv := newTusTestServer(t)
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithRetries(1)
+	srv.patchStatus = func(int) int { return http.StatusServiceUnavailable }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	data := tusTestData(5)
+	uploadURL, err := tus.Upload(ctx, TusUpload{Reader: bytes.NewReader(data), Size: 5})
+	if err == nil || err.StatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("Upload = %v, want erro 503", err)
+	}
+	if uploadURL != srv.URL+"/files/1" {
+		t.Errorf("Upload = %q, want a URL do upload criado", uploadURL)
+	}
+	if srv.patches != 2 {
+		t.Errorf("PATCHes = %d, want 2", srv.patches)
+	}
+}
+
+func TestTusTerminate(t *testing.T) {
+	srv := newTusTestServer(t)
+	store := NewMemoryUploadStore()
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithStore(store).WithChunkSize(10)
+	srv.patchStatus = func(int) int { return http.StatusForbidden }
+
+	data := tusTestData(25)
+	uploadURL, _ := tus.Upload(context.Background(), TusUpload{Reader: bytes.NewReader(data), Size: 25, Fingerprint: "arquivo"})
+
+	if err := tus.Terminate(context.Background(), uploadURL, "arquivo"); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if srv.upload("1") != nil {
+		t.Error("upload mantido no servidor após Terminate")
+	}
+	if stored, _ := store.Get("arquivo"); stored != "" {
+		t.Errorf("UploadStore mantém %q após Terminate", stored)
+	}
+	if _, _, err := tus.Offset(context.Background(), uploadURL); err == nil || err.StatusCode() != http.StatusNotFound {
+		t.Errorf("Offset após Terminate = %v, want erro 404", err)
+	}
+}
+
+// tusTestFailingStore é um UploadStore em memória cujo Delete sempre falha.
+type tusTestFailingStore struct {
+	*memoryUploadStore
+}
+
+var errTusTestStore = errors.New("disco cheio")
+
+func (s tusTestFailingStore) Delete(string) error { return errTusTestStore }
+
+func TestTusResumeStoreDeleteError(t *testing.T) {
+	srv := newTusTestServer(t)
+	store := tusTestFailingStore{NewMemoryUploadStore()}
+	store.Set("arquivo", srv.URL+"/files/expirado")
+	tus := NewRequest(srv.URL, nil, 5).Tus("/files/").WithStore(store)
+
+	data := tusTestData(5)
+	_, err := tus.Upload(context.Background(), TusUpload{Reader: bytes.NewReader(data), Size: 5, Fingerprint: "arquivo"})
+	if err == nil || !errors.Is(err, errTusTestStore) || !strings.Contains(err.Error(), "C: 09") {
+		t.Fatalf("Upload = %v, want erro C: 09 do UploadStore", err)
+	}
+	if got := srv.calls(0); fmt.Sprint(got) != fmt.Sprint([]string{"HEAD /files/expirado"}) {
+		t.Errorf("requisições = %v, want apenas o HEAD", got)
+	}
+}
+
+func TestTusInvalidRequest(t *testing.T) {
+	srv := newTusTestServer(t)
+	m := NewRequest(srv.URL, nil, 5)
+	m.request.SetHeader("X-Inválido", "valor")
+	tus := m.Tus("/files/")
+
+	data := tusTestData(5)
+	_, uploadErr := tus.Upload(context.Background(), TusUpload{Reader: bytes.NewReader(data), Size: 5})
+	_, _, offsetErr := tus.Offset(context.Background(), srv.URL+"/files/1")
+	terminateErr := tus.Terminate(context.Background(), srv.URL+"/files/1", "")
+
+	for name, err := range map[string]*httpError{"Upload": uploadErr, "Offset": offsetErr, "Terminate": terminateErr} {
+		if err == nil || !strings.HasPrefix(err.Error(), "Requisição inválida") {
+			t.Errorf("%s = %v, want erro de requisição inválida", name, err)
+		}
+	}
+	if got := srv.calls(0); len(got) != 0 {
+		t.Errorf("requisições = %v, want nenhuma", got)
+	}
+}
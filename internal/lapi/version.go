@@ -0,0 +1,9 @@
+package lapi
+
+// Version é a versão da biblioteca lapi.
+// É enviada no header User-Agent padrão de todas as requisições.
+const Version = "0.1.0"
+
+// userAgent é o valor padrão do header User-Agent.
+// Pode ser substituído definindo o header User-Agent na requisição.
+const userAgent = "lapi/" + Version + " (+https://github.com/pedrofreit4s/lapi)"
@@ -0,0 +1,779 @@
+package lapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// WSMessageType é o tipo de uma mensagem WebSocket.
+type WSMessageType int
+
+const (
+	// WSText é uma mensagem de texto (UTF-8).
+	WSText WSMessageType = 1
+
+	// WSBinary é uma mensagem binária.
+	WSBinary WSMessageType = 2
+)
+
+// Códigos de fechamento da conexão WebSocket (RFC 6455, seção 7.4.1).
+const (
+	WSCloseNormal          = 1000
+	WSCloseGoingAway       = 1001
+	WSCloseProtocolError   = 1002
+	WSCloseUnsupportedData = 1003
+	WSCloseNoStatus        = 1005
+	WSCloseAbnormal        = 1006
+	WSCloseInvalidPayload  = 1007
+	WSClosePolicyViolation = 1008
+	WSCloseTooLarge        = 1009
+	WSCloseInternalError   = 1011
+	WSCloseServiceRestart  = 1012
+	WSCloseTryAgainLater   = 1013
+)
+
+// websocketGUID é o GUID usado no cálculo de Sec-WebSocket-Accept.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// defaultWSMessageSize é o tamanho máximo padrão de uma mensagem recebida.
+const defaultWSMessageSize = 16 << 20
+
+// wsCloseTimeout é o tempo de espera pela confirmação do fechamento.
+const wsCloseTimeout = 2 * time.Second
+
+// CloseError é o erro retornado quando a conexão WebSocket é fechada,
+// pelo servidor ou por uma violação do protocolo.
+type CloseError struct {
+	// Code é o código de fechamento (ex: WSCloseNormal).
+	Code int
+
+	// Reason é o motivo informado no fechamento.
+	Reason string
+}
+
+// Error implementa a interface error do Go.
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("lapi: websocket fechado (%d)", e.Code)
+	}
+	return fmt.Sprintf("lapi: websocket fechado (%d): %s", e.Code, e.Reason)
+}
+
+// WSSender envia mensagens em uma conexão WebSocket. É recebido pela função de OnConnect.
+type WSSender interface {
+	WriteMessage(kind WSMessageType, data []byte) error
+	Ping(data []byte) error
+}
+
+// errWSClosed indica uso da conexão depois de Close.
+var errWSClosed = errors.New("lapi: conexão websocket fechada")
+
+// wsDialer configura e abre conexões WebSocket (RFC 6455) com a configuração
+// do modelo: URL base, headers, token Bearer, autenticação e TLS.
+//
+// Exemplo de uso:
+//
+//	conn, err := m.WebSocket("/stream").
+//	    WithPingInterval(30 * time.Second).
+//	    WithReconnect(5, time.Second).
+//	    Dial(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer conn.Close(WSCloseNormal, "")
+//
+//	for {
+//	    kind, data, err := conn.ReadMessage()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    handle(kind, data)
+//	}
+type wsDialer struct {
+	m    *model
+	path string
+	opts []CallOption
+
+	subprotocols   []string
+	maxMessageSize int64
+	pingInterval   time.Duration
+	fragmentSize   int
+	reconnects     int
+	reconnectDelay time.Duration
+	onConnect      func(WSSender) error
+}
+
+// WebSocket cria um cliente WebSocket para path, com o handshake feito pelo
+// transporte HTTP do modelo (a URL base usa http:// ou https://).
+//
+// Parâmetros:
+//   - path: Caminho do endpoint (ex: "/ws")
+//   - opts: Opções aplicadas ao handshake (ex: WithHeader)
+//
+// Exemplo:
+//
+//	conn, err := m.WebSocket("/ws", WithHeader("X-Tenant", "acme")).Dial(ctx)
+func (m *model) WebSocket(path string, opts ...CallOption) *wsDialer {
+	return &wsDialer{
+		m:              m,
+		path:           path,
+		opts:           opts,
+		maxMessageSize: defaultWSMessageSize,
+		reconnectDelay: time.Second,
+	}
+}
+
+// WithSubprotocols define os subprotocolos oferecidos em Sec-WebSocket-Protocol.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) WithSubprotocols(protocols ...string) *wsDialer {
+	d.subprotocols = protocols
+	return d
+}
+
+// WithMaxMessageSize define o tamanho máximo, em bytes, de uma mensagem recebida,
+// somando os fragmentos. Mensagens maiores fecham a conexão com WSCloseTooLarge.
+// Por padrão, 16 MiB; zero ou negativo restaura o padrão.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) WithMaxMessageSize(size int64) *wsDialer {
+	if size <= 0 {
+		size = defaultWSMessageSize
+	}
+	d.maxMessageSize = size
+	return d
+}
+
+// WithPingInterval envia um ping a cada intervalo. Se nada for recebido do servidor
+// em dois intervalos, a conexão é considerada perdida. Zero (padrão) desativa o ping.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) WithPingInterval(interval time.Duration) *wsDialer {
+	d.pingInterval = interval
+	return d
+}
+
+// WithFragmentSize divide as mensagens enviadas em frames de até size bytes.
+// Zero (padrão) envia cada mensagem em um único frame.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) WithFragmentSize(size int) *wsDialer {
+	d.fragmentSize = size
+	return d
+}
+
+// WithReconnect reconecta automaticamente quando a conexão cai (erro de rede,
+// ping sem resposta ou fechamento 1001, 1006, 1011, 1012 e 1013), com intervalo
+// exponencial a partir de delay (até 30s). A leitura continua na nova conexão.
+//
+// Parâmetros:
+//   - attempts: Tentativas seguidas antes de desistir
+//   - delay: Intervalo antes da primeira tentativa
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) WithReconnect(attempts int, delay time.Duration) *wsDialer {
+	d.reconnects = attempts
+	d.reconnectDelay = delay
+	return d
+}
+
+// OnConnect define uma função chamada após cada conexão, inclusive reconexões
+// (ex: para refazer inscrições). Se retornar erro, a conexão é fechada.
+//
+// Retorna o próprio cliente para permitir encadeamento de métodos.
+func (d *wsDialer) OnConnect(fn func(WSSender) error) *wsDialer {
+	d.onConnect = fn
+	return d
+}
+
+// Dial abre a conexão WebSocket. Quando ctx é cancelado, a conexão é fechada
+// com WSCloseGoingAway e as reconexões são interrompidas.
+//
+// Retorna a conexão ou um erro se o handshake falhar.
+func (d *wsDialer) Dial(ctx context.Context) (*wsConn, error) {
+	stream, err := d.handshake(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &wsConn{dialer: d, ctx: ctx, stream: stream}
+	if d.onConnect != nil {
+		if err := d.onConnect(conn); err != nil {
+			conn.Close(WSCloseNormal, "")
+			return nil, err
+		}
+	}
+	conn.stop = context.AfterFunc(ctx, func() {
+		conn.Close(WSCloseGoingAway, "")
+	})
+	return conn, nil
+}
+
+// handshake envia a requisição de upgrade e valida a resposta do servidor.
+func (d *wsDialer) handshake(ctx context.Context) (*wsStream, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	headers := []CallOption{
+		WithHeader("Connection", "Upgrade"),
+		WithHeader("Upgrade", "websocket"),
+		WithHeader("Sec-WebSocket-Version", "13"),
+		WithHeader("Sec-WebSocket-Key", key),
+		WithHeader("Accept-Encoding", "identity"),
+	}
+	if len(d.subprotocols) > 0 {
+		headers = append(headers, WithHeader("Sec-WebSocket-Protocol", strings.Join(d.subprotocols, ", ")))
+	}
+	options := newCallOptions(append(append(headers, d.opts...), WithContext(ctx)))
+	options.stream = true
+
+	resp, httpErr := d.m.exchange(http.MethodGet, d.path, nil, options)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, d.m.MakeError(resp.StatusCode, resp.Status, "Handshake WebSocket recusado")
+	}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("lapi: o transporte não suporta upgrade de protocolo")
+	}
+	fail := func(err error) (*wsStream, error) {
+		rwc.Close()
+		return nil, err
+	}
+
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(resp.Header.Get("Connection")), "upgrade") {
+		return fail(errors.New("lapi: resposta do handshake WebSocket sem Upgrade: websocket"))
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return fail(errors.New("lapi: Sec-WebSocket-Accept inválido"))
+	}
+	protocol := resp.Header.Get("Sec-WebSocket-Protocol")
+	if protocol != "" && !containsFold(d.subprotocols, protocol) {
+		return fail(fmt.Errorf("lapi: subprotocolo %q não foi oferecido", protocol))
+	}
+	if resp.Header.Get("Sec-WebSocket-Extensions") != "" {
+		return fail(errors.New("lapi: extensões WebSocket não são suportadas"))
+	}
+
+	stream := &wsStream{
+		rwc:      rwc,
+		br:       bufio.NewReader(rwc),
+		protocol: protocol,
+		done:     make(chan struct{}),
+	}
+	stream.touch()
+	if d.pingInterval > 0 {
+		go stream.keepAlive(d.pingInterval)
+	}
+	return stream, nil
+}
+
+// wsConn é uma conexão WebSocket. ReadMessage não deve ser chamada por mais de
+// uma goroutine ao mesmo tempo; WriteMessage, Ping e Close podem ser chamadas
+// de qualquer goroutine.
+type wsConn struct {
+	dialer *wsDialer
+	ctx    context.Context
+	stop   func() bool
+
+	mu     sync.Mutex
+	stream *wsStream
+	closed bool
+}
+
+// Subprotocol retorna o subprotocolo escolhido pelo servidor.
+func (c *wsConn) Subprotocol() string {
+	return c.current().protocol
+}
+
+// ReadMessage lê a próxima mensagem, juntando os fragmentos. Pings são
+// respondidos automaticamente. Quando o servidor fecha a conexão, retorna *CloseError.
+// Com WithReconnect, quedas da conexão são tratadas com uma nova conexão.
+func (c *wsConn) ReadMessage() (WSMessageType, []byte, error) {
+	for {
+		stream := c.current()
+		kind, data, err := stream.readMessage(c.dialer.maxMessageSize)
+		if err == nil {
+			return kind, data, nil
+		}
+		if !c.shouldReconnect(err) {
+			return 0, nil, err
+		}
+		if err := c.reconnect(stream); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// WriteMessage envia uma mensagem, dividida em fragmentos quando WithFragmentSize é usado.
+//
+// Exemplo:
+//
+//	err := conn.WriteMessage(WSText, []byte(`{"op":"subscribe","channel":"orders"}`))
+func (c *wsConn) WriteMessage(kind WSMessageType, data []byte) error {
+	if kind != WSText && kind != WSBinary {
+		return fmt.Errorf("lapi: tipo de mensagem websocket inválido: %d", kind)
+	}
+	if c.isClosed() {
+		return errWSClosed
+	}
+	return c.current().writeMessage(byte(kind), data, c.dialer.fragmentSize)
+}
+
+// Ping envia um ping com data (até 125 bytes).
+func (c *wsConn) Ping(data []byte) error {
+	if c.isClosed() {
+		return errWSClosed
+	}
+	return c.current().writeControl(opPing, data)
+}
+
+// Close inicia o fechamento da conexão com code e reason e aguarda a confirmação
+// do servidor por até 2 segundos. Reconexões deixam de ser feitas.
+func (c *wsConn) Close(code int, reason string) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	stream := c.stream
+	c.mu.Unlock()
+	if c.stop != nil {
+		c.stop()
+	}
+	return stream.close(code, reason)
+}
+
+// current retorna o stream da conexão atual.
+func (c *wsConn) current() *wsStream {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stream
+}
+
+// isClosed indica se Close já foi chamada.
+func (c *wsConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// shouldReconnect indica se o erro de leitura deve levar a uma reconexão.
+func (c *wsConn) shouldReconnect(err error) bool {
+	if c.dialer.reconnects <= 0 || c.isClosed() || c.ctx.Err() != nil {
+		return false
+	}
+	var closeErr *CloseError
+	if errors.As(err, &closeErr) {
+		switch closeErr.Code {
+		case WSCloseGoingAway, WSCloseAbnormal, WSCloseInternalError, WSCloseServiceRestart, WSCloseTryAgainLater:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// reconnect substitui o stream encerrado por uma nova conexão, com intervalo exponencial.
+func (c *wsConn) reconnect(old *wsStream) error {
+	old.rwc.Close()
+
+	delay := c.dialer.reconnectDelay
+	var lastErr error
+	for attempt := 0; attempt < c.dialer.reconnects; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return c.ctx.Err()
+		case <-timer.C:
+		}
+		if delay *= 2; delay > 30*time.Second {
+			delay = 30 * time.Second
+		}
+
+		stream, err := c.dialer.handshake(c.ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			stream.rwc.Close()
+			return errWSClosed
+		}
+		c.stream = stream
+		c.mu.Unlock()
+
+		if c.dialer.onConnect != nil {
+			if err := c.dialer.onConnect(c); err != nil {
+				lastErr = err
+				stream.rwc.Close()
+				continue
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("lapi: falha ao reconectar o websocket: %w", lastErr)
+}
+
+// containsFold indica se list contém value, ignorando maiúsculas e minúsculas.
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Opcodes dos frames WebSocket.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// wsStream é uma conexão WebSocket estabelecida (um handshake).
+type wsStream struct {
+	rwc      io.ReadWriteCloser
+	br       *bufio.Reader
+	protocol string
+
+	readMu  sync.Mutex
+	writeMu sync.Mutex
+
+	lastSeen  atomic.Int64
+	reading   atomic.Bool
+	closeSent atomic.Bool
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// touch registra o recebimento de dados do servidor.
+func (s *wsStream) touch() {
+	s.lastSeen.Store(time.Now().UnixNano())
+}
+
+// finish marca o stream como encerrado.
+func (s *wsStream) finish() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// keepAlive envia pings periódicos e fecha a conexão se o servidor parar de responder.
+// Os pongs só são lidos por ReadMessage, então o silêncio conta apenas durante uma leitura.
+func (s *wsStream) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if !s.reading.Load() {
+				s.touch()
+			}
+			if time.Since(time.Unix(0, s.lastSeen.Load())) > 2*interval {
+				s.rwc.Close()
+				return
+			}
+			if err := s.writeControl(opPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readMessage lê a próxima mensagem de dados completa.
+func (s *wsStream) readMessage(maxSize int64) (WSMessageType, []byte, error) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+	s.reading.Store(true)
+	defer s.reading.Store(false)
+
+	var kind WSMessageType
+	var message []byte
+	for {
+		fin, opcode, payload, err := s.readFrame(maxSize - int64(len(message)))
+		if err != nil {
+			s.finish()
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := s.writeControl(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, s.receiveClose(payload)
+		case opText, opBinary:
+			if kind != 0 {
+				return 0, nil, s.fail(WSCloseProtocolError, "nova mensagem antes do fim da anterior")
+			}
+			kind = WSMessageType(opcode)
+		case opContinuation:
+			if kind == 0 {
+				return 0, nil, s.fail(WSCloseProtocolError, "continuação sem mensagem iniciada")
+			}
+		default:
+			return 0, nil, s.fail(WSCloseProtocolError, fmt.Sprintf("opcode desconhecido %#x", opcode))
+		}
+
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+		if kind == WSText && !utf8.Valid(message) {
+			return 0, nil, s.fail(WSCloseInvalidPayload, "texto com UTF-8 inválido")
+		}
+		if message == nil {
+			message = []byte{}
+		}
+		return kind, message, nil
+	}
+}
+
+// readFrame lê um frame. O payload de dados é limitado a remaining bytes.
+func (s *wsStream) readFrame(remaining int64) (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(s.br, header[:]); err != nil {
+		return false, 0, nil, s.abnormal(err)
+	}
+	s.touch()
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, s.fail(WSCloseProtocolError, "bits RSV definidos sem extensão")
+	}
+	if header[1]&0x80 != 0 {
+		return false, 0, nil, s.fail(WSCloseProtocolError, "frame do servidor mascarado")
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(s.br, ext[:]); err != nil {
+			return false, 0, nil, s.abnormal(err)
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(s.br, ext[:]); err != nil {
+			return false, 0, nil, s.abnormal(err)
+		}
+		size := binary.BigEndian.Uint64(ext[:])
+		if size>>63 != 0 {
+			return false, 0, nil, s.fail(WSCloseProtocolError, "tamanho de frame inválido")
+		}
+		length = int64(size)
+	}
+
+	if opcode >= opClose {
+		if !fin || length > 125 {
+			return false, 0, nil, s.fail(WSCloseProtocolError, "frame de controle inválido")
+		}
+	} else if remaining >= 0 && length > remaining {
+		return false, 0, nil, s.fail(WSCloseTooLarge, "mensagem excede o tamanho máximo")
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(s.br, payload); err != nil {
+		return false, 0, nil, s.abnormal(err)
+	}
+	return fin, opcode, payload, nil
+}
+
+// receiveClose trata o frame de fechamento do servidor, confirma o fechamento e encerra a conexão.
+func (s *wsStream) receiveClose(payload []byte) error {
+	closeErr := &CloseError{Code: WSCloseNoStatus}
+	switch {
+	case len(payload) == 1:
+		return s.fail(WSCloseProtocolError, "frame de fechamento inválido")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return s.fail(WSCloseProtocolError, "código de fechamento inválido")
+		}
+		if !utf8.ValidString(closeErr.Reason) {
+			return s.fail(WSCloseInvalidPayload, "motivo com UTF-8 inválido")
+		}
+	}
+
+	if !s.closeSent.Load() {
+		code := closeErr.Code
+		if code == WSCloseNoStatus {
+			code = WSCloseNormal
+		}
+		s.writeClose(code, "")
+	}
+	s.rwc.Close()
+	s.finish()
+	return closeErr
+}
+
+// fail fecha a conexão por uma violação do protocolo e retorna o erro correspondente.
+func (s *wsStream) fail(code int, reason string) error {
+	s.writeClose(code, reason)
+	s.rwc.Close()
+	s.finish()
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// abnormal converte um erro de leitura em um fechamento anormal (1006).
+func (s *wsStream) abnormal(err error) error {
+	s.rwc.Close()
+	s.finish()
+	return fmt.Errorf("%w: %w", &CloseError{Code: WSCloseAbnormal}, err)
+}
+
+// close envia o frame de fechamento e aguarda a confirmação do servidor.
+func (s *wsStream) close(code int, reason string) error {
+	err := s.writeClose(code, reason)
+
+	timer := time.AfterFunc(wsCloseTimeout, func() { s.rwc.Close() })
+	defer timer.Stop()
+
+	if s.readMu.TryLock() {
+		// No reader is active: wait for the close frame here
+		for {
+			_, opcode, _, err := s.readFrame(-1)
+			if err != nil || opcode == opClose {
+				break
+			}
+		}
+		s.readMu.Unlock()
+		s.finish()
+	} else {
+		<-s.done
+	}
+
+	s.rwc.Close()
+	return err
+}
+
+// writeClose envia o frame de fechamento uma única vez.
+func (s *wsStream) writeClose(code int, reason string) error {
+	if s.closeSent.Swap(true) {
+		return nil
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > 125 {
+		payload = payload[:125]
+	}
+	return s.writeControl(opClose, payload)
+}
+
+// writeControl envia um frame de controle (ping, pong ou close).
+func (s *wsStream) writeControl(opcode byte, payload []byte) error {
+	if len(payload) > 125 {
+		return errors.New("lapi: payload de controle websocket excede 125 bytes")
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.writeFrame(true, opcode, payload)
+}
+
+// writeMessage envia uma mensagem de dados, dividida em frames de até fragmentSize bytes.
+func (s *wsStream) writeMessage(opcode byte, data []byte, fragmentSize int) error {
+	if s.closeSent.Load() {
+		return errWSClosed
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if fragmentSize <= 0 || len(data) <= fragmentSize {
+		return s.writeFrame(true, opcode, data)
+	}
+	for len(data) > 0 {
+		n := min(fragmentSize, len(data))
+		if err := s.writeFrame(n == len(data), opcode, data[:n]); err != nil {
+			return err
+		}
+		data, opcode = data[n:], opContinuation
+	}
+	return nil
+}
+
+// writeFrame escreve um frame mascarado, como exigido dos clientes. Deve ser chamada com writeMu travado.
+func (s *wsStream) writeFrame(fin bool, opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame = append(frame, first)
+
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := s.rwc.Write(frame)
+	return err
+}
+
+// validCloseCode indica se o código pode ser recebido em um frame de fechamento.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 3000 && code <= 4999:
+		return true
+	case code < 1000 || code > 1014:
+		return false
+	case code == 1004 || code == WSCloseNoStatus || code == WSCloseAbnormal:
+		return false
+	}
+	return true
+}
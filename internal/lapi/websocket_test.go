@@ -0,0 +1,438 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// wsTestFrame é um frame recebido pelo servidor de teste.
+type wsTestFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+	masked  bool
+}
+
+// newWSEchoServer cria um servidor WebSocket mínimo que devolve cada mensagem
+// recebida em dois fragmentos, com um ping entre eles. A mensagem "fechar" faz o
+// servidor iniciar o fechamento, "cair" derruba a conexão sem fechamento e
+// "silenciar" faz o servidor parar de responder pings. Os frames recebidos do
+// cliente são enviados em frames.
+func newWSEchoServer(t *testing.T) (*httptest.Server, <-chan wsTestFrame) {
+	t.Helper()
+	frames := make(chan wsTestFrame, 64)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + websocketGUID))
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n",
+			base64.StdEncoding.EncodeToString(sum[:]))
+		if strings.Contains(r.Header.Get("Sec-WebSocket-Protocol"), "chat") {
+			fmt.Fprint(rw, "Sec-WebSocket-Protocol: chat\r\n")
+		}
+		fmt.Fprint(rw, "\r\n")
+		rw.Flush()
+
+		var message []byte
+		var kind byte
+		var silent bool
+		for {
+			frame, err := wsTestReadFrame(rw.Reader)
+			if err != nil {
+				return
+			}
+			frames <- frame
+
+			switch frame.opcode {
+			case opClose:
+				wsTestWriteFrame(conn, true, opClose, frame.payload)
+				return
+			case opPing:
+				if !silent {
+					wsTestWriteFrame(conn, true, opPong, frame.payload)
+				}
+				continue
+			case opPong:
+				continue
+			case opText, opBinary:
+				kind, message = frame.opcode, nil
+			}
+			message = append(message, frame.payload...)
+			if !frame.fin {
+				continue
+			}
+
+			switch string(message) {
+			case "fechar":
+				wsTestWriteFrame(conn, true, opClose, append([]byte{0x03, 0xe8}, "fim"...))
+				continue
+			case "cair":
+				return
+			case "silenciar":
+				silent = true
+				continue
+			}
+			half := len(message) / 2
+			wsTestWriteFrame(conn, false, kind, message[:half])
+			wsTestWriteFrame(conn, true, opPing, []byte("eco"))
+			wsTestWriteFrame(conn, true, opContinuation, message[half:])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, frames
+}
+
+// wsTestReadFrame lê um frame do cliente, removendo a máscara.
+func wsTestReadFrame(r io.Reader) (wsTestFrame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return wsTestFrame{}, err
+	}
+	frame := wsTestFrame{fin: header[0]&0x80 != 0, opcode: header[0] & 0x0f, masked: header[1]&0x80 != 0}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	var mask [4]byte
+	if frame.masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return frame, err
+		}
+	}
+	frame.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, frame.payload); err != nil {
+		return frame, err
+	}
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i%4]
+	}
+	return frame, nil
+}
+
+// wsTestWriteFrame escreve um frame do servidor, sem máscara.
+func wsTestWriteFrame(w io.Writer, fin bool, opcode byte, payload []byte) error {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	_, err := w.Write(append(frame, payload...))
+	return err
+}
+
+// nextWSFrame retorna o próximo frame recebido pelo servidor.
+func nextWSFrame(t *testing.T, frames <-chan wsTestFrame) wsTestFrame {
+	t.Helper()
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(5 * time.Second):
+		t.Fatal("nenhum frame recebido pelo servidor")
+		return wsTestFrame{}
+	}
+}
+
+func dialWSTest(t *testing.T, srv *httptest.Server, configure func(*wsDialer)) *wsConn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	dialer := NewRequest(srv.URL, nil, 5).WebSocket("/ws")
+	if configure != nil {
+		configure(dialer)
+	}
+	conn, err := dialer.Dial(ctx)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(WSCloseNormal, "") })
+	return conn
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	srv, frames := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, func(d *wsDialer) { d.WithSubprotocols("chat", "superchat") })
+
+	if got := conn.Subprotocol(); got != "chat" {
+		t.Errorf("Subprotocol() = %q, want %q", got, "chat")
+	}
+
+	tests := []struct {
+		name string
+		kind WSMessageType
+		data []byte
+	}{
+		{"texto", WSText, []byte("olá, mundo")},
+		{"binário", WSBinary, []byte{0x00, 0xff, 0x10, 0x80}},
+		{"tamanho de 16 bits", WSBinary, bytes.Repeat([]byte("a"), 300)},
+		{"tamanho de 64 bits", WSBinary, bytes.Repeat([]byte("b"), 70000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := conn.WriteMessage(tt.kind, tt.data); err != nil {
+				t.Fatalf("WriteMessage: %v", err)
+			}
+
+			frame := nextWSFrame(t, frames)
+			if !frame.masked {
+				t.Error("frame do cliente sem máscara")
+			}
+			if !frame.fin || frame.opcode != byte(tt.kind) || !bytes.Equal(frame.payload, tt.data) {
+				t.Errorf("frame = fin %v opcode %d (%d bytes), want fin true opcode %d (%d bytes)",
+					frame.fin, frame.opcode, len(frame.payload), tt.kind, len(tt.data))
+			}
+
+			kind, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			if kind != tt.kind || !bytes.Equal(data, tt.data) {
+				t.Errorf("ReadMessage = %d (%d bytes), want %d (%d bytes)", kind, len(data), tt.kind, len(tt.data))
+			}
+
+			// The ping between the echoed fragments is answered automatically
+			pong := nextWSFrame(t, frames)
+			if pong.opcode != opPong || string(pong.payload) != "eco" || !pong.masked {
+				t.Errorf("pong = opcode %d payload %q masked %v", pong.opcode, pong.payload, pong.masked)
+			}
+		})
+	}
+}
+
+func TestWebSocketFragmentation(t *testing.T) {
+	srv, frames := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, func(d *wsDialer) { d.WithFragmentSize(4) })
+
+	if err := conn.WriteMessage(WSText, []byte("fragmentado")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+
+	want := []wsTestFrame{
+		{fin: false, opcode: opText, payload: []byte("frag")},
+		{fin: false, opcode: opContinuation, payload: []byte("ment")},
+		{fin: true, opcode: opContinuation, payload: []byte("ado")},
+	}
+	for i, w := range want {
+		frame := nextWSFrame(t, frames)
+		if frame.fin != w.fin || frame.opcode != w.opcode || !bytes.Equal(frame.payload, w.payload) || !frame.masked {
+			t.Errorf("frame %d = fin %v opcode %d %q masked %v, want fin %v opcode %d %q masked true",
+				i, frame.fin, frame.opcode, frame.payload, frame.masked, w.fin, w.opcode, w.payload)
+		}
+	}
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if string(data) != "fragmentado" {
+		t.Errorf("ReadMessage = %q, want %q", data, "fragmentado")
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	srv, frames := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, nil)
+
+	if err := conn.Close(WSCloseNormal, "tchau"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	frame := nextWSFrame(t, frames)
+	if frame.opcode != opClose || len(frame.payload) < 2 {
+		t.Fatalf("frame = opcode %d payload %q, want close", frame.opcode, frame.payload)
+	}
+	if code := binary.BigEndian.Uint16(frame.payload); code != WSCloseNormal || string(frame.payload[2:]) != "tchau" {
+		t.Errorf("close = %d %q, want %d %q", code, frame.payload[2:], WSCloseNormal, "tchau")
+	}
+
+	if err := conn.WriteMessage(WSText, []byte("depois")); !errors.Is(err, errWSClosed) {
+		t.Errorf("WriteMessage depois de Close = %v, want %v", err, errWSClosed)
+	}
+}
+
+func TestWebSocketServerClose(t *testing.T) {
+	srv, frames := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, nil)
+
+	if err := conn.WriteMessage(WSText, []byte("fechar")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	nextWSFrame(t, frames)
+
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != WSCloseNormal || closeErr.Reason != "fim" {
+		t.Fatalf("ReadMessage = %v, want CloseError %d %q", err, WSCloseNormal, "fim")
+	}
+
+	// The client confirms the close with the same code
+	frame := nextWSFrame(t, frames)
+	if frame.opcode != opClose || len(frame.payload) < 2 || binary.BigEndian.Uint16(frame.payload) != WSCloseNormal {
+		t.Errorf("confirmação = opcode %d payload %q, want close %d", frame.opcode, frame.payload, WSCloseNormal)
+	}
+}
+
+func TestWebSocketHandshakeRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+	}{
+		{"sem upgrade", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}, http.StatusForbidden},
+		{"accept inválido", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Upgrade", "websocket")
+			w.Header().Set("Connection", "Upgrade")
+			w.Header().Set("Sec-WebSocket-Accept", "invalido")
+			w.WriteHeader(http.StatusSwitchingProtocols)
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			conn, err := NewRequest(srv.URL, nil, 5).WebSocket("/ws").Dial(context.Background())
+			if err == nil {
+				conn.Close(WSCloseNormal, "")
+				t.Fatal("Dial sem erro, want erro de handshake")
+			}
+			var httpErr HttpError
+			if tt.status != 0 && (!errors.As(err, &httpErr) || httpErr.StatusCode() != tt.status) {
+				t.Errorf("Dial = %v, want HttpError %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestWebSocketReconnect(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		reconnect bool
+	}{
+		{"queda da conexão", "cair", true},
+		{"fechamento normal", "fechar", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newWSEchoServer(t)
+			var connects atomic.Int32
+			conn := dialWSTest(t, srv, func(d *wsDialer) {
+				d.WithReconnect(3, 10*time.Millisecond).OnConnect(func(s WSSender) error {
+					return s.WriteMessage(WSText, []byte(fmt.Sprintf("conexão %d", connects.Add(1))))
+				})
+			})
+
+			if _, data, err := conn.ReadMessage(); err != nil || string(data) != "conexão 1" {
+				t.Fatalf("ReadMessage = %q, %v, want %q", data, err, "conexão 1")
+			}
+			if err := conn.WriteMessage(WSText, []byte(tt.message)); err != nil {
+				t.Fatalf("WriteMessage: %v", err)
+			}
+
+			_, data, err := conn.ReadMessage()
+			if tt.reconnect {
+				if err != nil || string(data) != "conexão 2" {
+					t.Errorf("ReadMessage = %q, %v, want %q na nova conexão", data, err, "conexão 2")
+				}
+				return
+			}
+			var closeErr *CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != WSCloseNormal {
+				t.Errorf("ReadMessage = %v, want CloseError %d", err, WSCloseNormal)
+			}
+			if n := connects.Load(); n != 1 {
+				t.Errorf("conexões = %d, want 1 (sem reconexão)", n)
+			}
+		})
+	}
+}
+
+func TestWebSocketKeepAlive(t *testing.T) {
+	srv, frames := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, func(d *wsDialer) { d.WithPingInterval(50 * time.Millisecond) })
+
+	if frame := nextWSFrame(t, frames); frame.opcode != opPing || !frame.masked {
+		t.Fatalf("frame = opcode %d masked %v, want ping mascarado", frame.opcode, frame.masked)
+	}
+
+	// Without pongs the connection is dropped after two intervals
+	if err := conn.WriteMessage(WSText, []byte("silenciar")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		var closeErr *CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != WSCloseAbnormal {
+			t.Errorf("ReadMessage = %v, want CloseError %d", err, WSCloseAbnormal)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("conexão sem pongs não foi encerrada")
+	}
+}
+
+func TestWebSocketMaxMessageSizeDefault(t *testing.T) {
+	srv, _ := newWSEchoServer(t)
+	conn := dialWSTest(t, srv, func(d *wsDialer) { d.WithMaxMessageSize(0) })
+
+	if err := conn.WriteMessage(WSText, []byte("olá")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "olá" {
+		t.Errorf("ReadMessage = %q, %v, want %q", data, err, "olá")
+	}
+}